@@ -0,0 +1,202 @@
+package ringmap
+
+// ARCPolicy is the Adaptive Replacement Cache policy of Megiddo and Modha. It
+// balances recency and frequency on its own, without tuning.
+//
+// Keys that were used once are kept in T1, and keys that were used again in T2,
+// both in LRU order. The keys last evicted from either list are remembered,
+// without their values, in the ghost lists B1 and B2. A new key that is found
+// in B1 means T1 was too small, and one found in B2 means T2 was; either way
+// the target size of T1 moves towards the list that missed, and the key goes
+// straight to T2. All four lists are sized from the capacity of the map: T1
+// and B1 together hold at most capacity keys, and all lists at most twice the
+// capacity.
+//
+// A map with a weight limit may evict more elements than ARC expects, which
+// only makes the lists smaller.
+type ARCPolicy[K comparable] struct {
+	o       Ordering[K]
+	entries map[K]*keyEntry[K]
+	t1, t2  keyList[K]
+	b1, b2  keyList[K]
+	target  int // the target size of T1
+
+	fromB2  bool // whether the candidate was found in B2
+	discard bool // whether the next victim from T1 is forgotten
+	victim  *keyEntry[K]
+}
+
+// NewARCPolicy creates a new ARC eviction policy.
+func NewARCPolicy[K comparable]() *ARCPolicy[K] {
+	return &ARCPolicy[K]{entries: make(map[K]*keyEntry[K])}
+}
+
+// NewARCRingMapOf creates a new ordered map in ARC mode with a maximum size
+// that holds keys of type K and values of type V.
+func NewARCRingMapOf[K comparable, V any](capacity int) *RingMapOf[K, V] {
+	return NewRingMapWithPolicy[K, V](capacity, NewARCPolicy[K]())
+}
+
+// Target returns the size that the policy currently aims for T1, the list of
+// keys that were only used once, to have. It is between zero and the capacity
+// of the map.
+func (p *ARCPolicy[K]) Target() int {
+	return p.target
+}
+
+// Attach implements EvictionPolicy.
+func (p *ARCPolicy[K]) Attach(o Ordering[K]) {
+	p.o = o
+}
+
+// OnCandidate implements CandidatePolicy. A key found in a ghost list adapts
+// the target size of T1; otherwise the ghost lists make room for the key.
+func (p *ARCPolicy[K]) OnCandidate(key K) {
+	p.fromB2, p.discard = false, false
+	c := p.o.Capacity()
+	if c <= 0 {
+		return
+	}
+
+	e := p.entries[key]
+	switch {
+	case e != nil && e.list == &p.b1:
+		delta := 1
+		if p.b2.len > p.b1.len {
+			delta = p.b2.len / p.b1.len
+		}
+		p.target += delta
+		if p.target > c {
+			p.target = c
+		}
+	case e != nil && e.list == &p.b2:
+		delta := 1
+		if p.b1.len > p.b2.len {
+			delta = p.b1.len / p.b2.len
+		}
+		p.target -= delta
+		if p.target < 0 {
+			p.target = 0
+		}
+		p.fromB2 = true
+	case p.t1.len+p.b1.len >= c:
+		if p.t1.len < c {
+			p.forget(p.b1.head)
+		} else {
+			// T1 fills the whole map, so its LRU key is evicted without
+			// being remembered.
+			p.discard = true
+		}
+	case p.t1.len+p.t2.len+p.b1.len+p.b2.len >= 2*c:
+		p.forget(p.b2.head)
+	}
+}
+
+// OnInsert implements EvictionPolicy. A key from a ghost list goes to T2, and
+// any other key to T1.
+func (p *ARCPolicy[K]) OnInsert(key K) {
+	p.fromB2, p.discard = false, false
+	if e, ok := p.entries[key]; ok {
+		e.moveTo(&p.t2)
+	} else {
+		e := &keyEntry[K]{key: key}
+		p.entries[key] = e
+		p.t1.push(e)
+	}
+	p.trim()
+}
+
+// OnAccess implements EvictionPolicy. It moves key to the MRU end of T2.
+func (p *ARCPolicy[K]) OnAccess(key K) {
+	p.promote(key)
+}
+
+// OnUpdate implements EvictionPolicy. It moves key to the MRU end of T2.
+func (p *ARCPolicy[K]) OnUpdate(key K) {
+	p.promote(key)
+}
+
+// OnDelete implements EvictionPolicy. The victim of an eviction moves to the
+// ghost list for its list; any other key is forgotten.
+func (p *ARCPolicy[K]) OnDelete(key K) {
+	e, ok := p.entries[key]
+	if !ok {
+		return
+	}
+
+	victim := e == p.victim
+	p.victim = nil
+	switch {
+	case !victim || p.o.Capacity() <= 0 || (e.list == &p.t1 && p.discard):
+		p.forget(e)
+	case e.list == &p.t1:
+		e.moveTo(&p.b1)
+	case e.list == &p.t2:
+		e.moveTo(&p.b2)
+	}
+	p.trim()
+}
+
+// Victim implements EvictionPolicy. It returns the LRU key of T1 if T1 is
+// larger than its target, and the LRU key of T2 otherwise.
+func (p *ARCPolicy[K]) Victim() (K, bool) {
+	var e *keyEntry[K]
+	switch {
+	case p.t1.len > 0 && (p.t1.len > p.target || (p.fromB2 && p.t1.len == p.target)):
+		e = p.t1.head
+	case p.t2.len > 0:
+		e = p.t2.head
+	default:
+		e = p.t1.head
+	}
+	if e == nil {
+		var zero K
+		return zero, false
+	}
+
+	p.victim = e
+	return e.key, true
+}
+
+// promote moves a key in T1 or T2 to the MRU end of T2.
+func (p *ARCPolicy[K]) promote(key K) {
+	e, ok := p.entries[key]
+	if !ok || (e.list != &p.t1 && e.list != &p.t2) {
+		return
+	}
+
+	e.moveTo(&p.t2)
+}
+
+// forget removes e from its list and from the policy. It does nothing for nil.
+func (p *ARCPolicy[K]) forget(e *keyEntry[K]) {
+	if e == nil {
+		return
+	}
+
+	e.list.remove(e)
+	delete(p.entries, e.key)
+}
+
+// trim shortens the ghost lists after the map shrank or lost keys in other
+// ways than ARC expects.
+func (p *ARCPolicy[K]) trim() {
+	c := p.o.Capacity()
+	if c < 0 {
+		return
+	}
+
+	for p.b1.len > 0 && p.t1.len+p.b1.len > c {
+		p.forget(p.b1.head)
+	}
+	for p.b1.len+p.b2.len > 0 && p.t1.len+p.t2.len+p.b1.len+p.b2.len > 2*c {
+		if p.b2.len > 0 {
+			p.forget(p.b2.head)
+		} else {
+			p.forget(p.b1.head)
+		}
+	}
+	if p.target > c {
+		p.target = c
+	}
+}
@@ -0,0 +1,145 @@
+package ringmap_test
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+// keySet returns n keys with a prefix.
+func keySet(prefix string, n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = prefix + strconv.Itoa(i)
+	}
+
+	return keys
+}
+
+// loop returns a trace that goes through keys in order, times times.
+func loop(keys []string, times int) []string {
+	var trace []string
+	for i := 0; i < times; i++ {
+		trace = append(trace, keys...)
+	}
+
+	return trace
+}
+
+func TestARCPolicy(t *testing.T) {
+	t.Run("GhostHits", func(t *testing.T) {
+		p := ringmap.NewARCPolicy[string]()
+		m := ringmap.NewRingMapWithPolicy[string, int](2, p)
+		m.Set("a", 1)
+		m.Get("a") // T2
+		m.Set("b", 2)
+		m.Set("c", 3) // b goes to B1
+		assert.ElementsMatch(t, []string{"a", "c"}, m.Keys())
+		assert.Equal(t, 0, p.Target())
+
+		// Missing b in B1 makes T1 larger, at the expense of a in T2.
+		m.Set("b", 2)
+		assert.ElementsMatch(t, []string{"b", "c"}, m.Keys())
+		assert.Equal(t, 1, p.Target())
+
+		// Missing a in B2 makes T1 smaller again.
+		m.Set("a", 1)
+		assert.ElementsMatch(t, []string{"a", "b"}, m.Keys())
+		assert.Equal(t, 0, p.Target())
+	})
+
+	t.Run("FullT1IsNotRemembered", func(t *testing.T) {
+		p := ringmap.NewARCPolicy[string]()
+		m := ringmap.NewRingMapWithPolicy[string, int](2, p)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		assert.Equal(t, []string{"b", "c"}, m.Keys())
+
+		// a isn't a ghost, so it doesn't adapt anything.
+		m.Set("a", 1)
+		assert.Equal(t, []string{"c", "a"}, m.Keys())
+		assert.Equal(t, 0, p.Target())
+	})
+
+	t.Run("DeleteForgets", func(t *testing.T) {
+		p := ringmap.NewARCPolicy[string]()
+		m := ringmap.NewRingMapWithPolicy[string, int](2, p)
+		m.Set("a", 1)
+		m.Get("a")
+		m.Set("b", 2)
+		m.Delete("b")
+		assert.Equal(t, 1, m.Len())
+
+		// b isn't a ghost after a delete.
+		m.Set("b", 2)
+		assert.Equal(t, 0, p.Target())
+		assert.Equal(t, []string{"a", "b"}, m.Keys())
+	})
+
+	t.Run("TargetStaysInCapacity", func(t *testing.T) {
+		p := ringmap.NewARCPolicy[int]()
+		m := ringmap.NewRingMapWithPolicy[int, int](10, p)
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 10000; i++ {
+			key := r.Intn(40)
+			if _, ok := m.Get(key); !ok {
+				m.Set(key, i)
+			}
+			assert.True(t, p.Target() >= 0 && p.Target() <= 10)
+			assert.LessOrEqual(t, m.Len(), 10)
+		}
+
+		m.Resize(4)
+		assert.LessOrEqual(t, p.Target(), 4)
+		assert.Equal(t, 4, m.Len())
+	})
+}
+
+func TestARCPolicy_Trace(t *testing.T) {
+	t.Run("ScanKeepsHotset", func(t *testing.T) {
+		hot := keySet("hot", 50)
+		trace := append(loop(hot, 3), keySet("scan", 1000)...)
+
+		arc := ringmap.NewARCRingMapOf[string, int](100)
+		replay(arc, trace)
+		lru := ringmap.NewLRURingMapOf[string, int](100)
+		replay(lru, trace)
+
+		// The hot keys are in T2, and the scan only goes through T1.
+		assert.Equal(t, 1.0, replay(arc, hot))
+		assert.Equal(t, 0.0, replay(lru, hot))
+	})
+
+	t.Run("Adapts", func(t *testing.T) {
+		p := ringmap.NewARCPolicy[string]()
+		m := ringmap.NewRingMapWithPolicy[string, int](100, p)
+
+		// A hot set fills most of T2.
+		replay(m, loop(keySet("hot", 80), 3))
+		assert.Equal(t, 0, p.Target())
+
+		// A loop that doesn't fit next to it hits in B1 and makes room for
+		// itself in T1, until it is all in the map.
+		recent := keySet("recent", 40)
+		replay(m, loop(recent, 10))
+		target := p.Target()
+		assert.Greater(t, target, 20)
+		assert.Equal(t, 1.0, replay(m, recent))
+
+		// The hot set coming back hits in B2 and takes the room back.
+		replay(m, loop(keySet("hot", 80), 10))
+		assert.Less(t, p.Target(), target)
+	})
+
+	t.Run("HotsetBeatsLRU", func(t *testing.T) {
+		trace := hotsetTrace(rand.New(rand.NewSource(1)), 20000, 50, "")
+
+		lru := replay(ringmap.NewLRURingMapOf[string, int](100), trace)
+		arc := replay(ringmap.NewARCRingMapOf[string, int](100), trace)
+		assert.Greater(t, arc, lru+0.05)
+	})
+}
@@ -0,0 +1,74 @@
+package ringmap
+
+// ClockPolicy is the CLOCK policy, also known as second chance. A read only
+// sets a visited bit on its key, so SyncRingMap serves Get and GetOrDefault
+// under its read lock.
+//
+// The front of the map is the hand of the clock. To find a victim, visited
+// keys at the front have their bit cleared and move to the back, and the
+// first key that wasn't visited is evicted. Unlike FIFO and SIEVE, CLOCK
+// therefore doesn't keep the map in insertion order: Keys() and Front()
+// report the keys in the order the hand gets to them, and a key that got a
+// second chance comes after the keys inserted before it moved. A hand that
+// swept the keys in insertion order instead would make it SievePolicy, which
+// is the one to use when the map has to stay in insertion order.
+//
+// Not to be confused with Clock, which tells the time for expiration.
+type ClockPolicy[K comparable] struct {
+	o       Ordering[K]
+	visited visitedBits[K]
+}
+
+// NewClockPolicy creates a new CLOCK eviction policy.
+func NewClockPolicy[K comparable]() *ClockPolicy[K] {
+	return &ClockPolicy[K]{visited: newVisitedBits[K]()}
+}
+
+// NewClockRingMapOf creates a new ordered map in CLOCK mode with a maximum size
+// that holds keys of type K and values of type V.
+func NewClockRingMapOf[K comparable, V any](capacity int) *RingMapOf[K, V] {
+	return NewRingMapWithPolicy[K, V](capacity, NewClockPolicy[K]())
+}
+
+// Attach implements EvictionPolicy.
+func (p *ClockPolicy[K]) Attach(o Ordering[K]) {
+	p.o = o
+}
+
+// OnInsert implements EvictionPolicy. New keys aren't visited.
+func (p *ClockPolicy[K]) OnInsert(key K) {
+	p.visited.add(key)
+}
+
+// OnAccess implements EvictionPolicy. It marks key as visited.
+func (p *ClockPolicy[K]) OnAccess(key K) {
+	p.visited.set(key)
+}
+
+// OnUpdate implements EvictionPolicy. It marks key as visited.
+func (p *ClockPolicy[K]) OnUpdate(key K) {
+	p.visited.set(key)
+}
+
+// OnDelete implements EvictionPolicy.
+func (p *ClockPolicy[K]) OnDelete(key K) {
+	p.visited.remove(key)
+}
+
+// Victim implements EvictionPolicy. It gives the visited keys at the front a
+// second chance at the back, and returns the first key that wasn't visited.
+func (p *ClockPolicy[K]) Victim() (K, bool) {
+	for {
+		key, ok := p.o.Front()
+		if !ok || !p.visited.clear(key) {
+			return key, ok
+		}
+		p.o.MoveToBack(key)
+	}
+}
+
+// ConcurrentAccess implements ConcurrentAccessPolicy. Accesses only set a
+// visited bit atomically.
+func (p *ClockPolicy[K]) ConcurrentAccess() bool {
+	return true
+}
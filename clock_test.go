@@ -0,0 +1,79 @@
+package ringmap_test
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockPolicy(t *testing.T) {
+	t.Run("SecondChance", func(t *testing.T) {
+		m := ringmap.NewClockRingMapOf[string, int](3)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		m.Get("a")
+		m.Set("d", 4)
+		assert.Equal(t, []string{"c", "a", "d"}, m.Keys())
+
+		// a's bit was cleared when it moved, so it goes the next time the
+		// hand gets to it.
+		m.Set("e", 5)
+		m.Set("f", 6)
+		assert.Equal(t, []string{"d", "e", "f"}, m.Keys())
+	})
+
+	t.Run("FrontIsHand", func(t *testing.T) {
+		m := ringmap.NewClockRingMapOf[string, int](3)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		m.Get("a")
+		m.Get("b")
+		m.Get("c")
+
+		// Every key was visited, so they all go around once.
+		m.Set("d", 4)
+		assert.Equal(t, []string{"b", "c", "d"}, m.Keys())
+		assert.Equal(t, "b", m.FrontEntry().Key())
+	})
+
+	t.Run("ConcurrentAccess", func(t *testing.T) {
+		var p interface{} = ringmap.NewClockPolicy[int]()
+		c, ok := p.(ringmap.ConcurrentAccessPolicy)
+		assert.True(t, ok)
+		assert.True(t, c.ConcurrentAccess())
+	})
+}
+
+func TestClockPolicy_Trace(t *testing.T) {
+	trace := hotsetTrace(rand.New(rand.NewSource(1)), 20000, 50, "")
+
+	fifo := replay(ringmap.NewRingMapOf[string, int](100), trace)
+	clock := replay(ringmap.NewClockRingMapOf[string, int](100), trace)
+	assert.Greater(t, clock, fifo+0.1)
+}
+
+func TestSyncRingMap_Clock(t *testing.T) {
+	s := ringmap.Synchronize(ringmap.NewClockRingMapOf[string, int](100))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := strconv.Itoa((g + i) % 150)
+				if _, ok := s.Get(key); !ok {
+					s.Set(key, i)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 100, s.Len())
+}
@@ -0,0 +1,637 @@
+package ringmap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operations recorded in a write-ahead log.
+const (
+	walSet byte = iota + 1
+	walPut
+	walDelete
+	walEvict
+)
+
+// DefaultCompactionSize is the size a write-ahead log grows to before it is
+// compacted into a snapshot, unless WithCompactionSize says otherwise.
+const DefaultCompactionSize = 4 << 20
+
+// DurableRingMap is a RingMap whose changes are written to a write-ahead log,
+// so they survive a crash. Every Set, Put and Delete is appended to the log,
+// along with the entries it evicted. Opening the map again loads the last
+// snapshot and replays the log on top of it. Once the log grows past the
+// compaction size it is replaced by a new snapshot.
+//
+// Reads don't go to the log. In LRU mode that means the order of the recovered
+// map follows the writes, not the reads.
+//
+// A DurableRingMap is safe for concurrent use by multiple goroutines.
+type DurableRingMap[K comparable, V any] struct {
+	mu   sync.Mutex
+	m    *RingMapOf[K, V]
+	dir  string
+	seq  uint64
+	log  *os.File
+	size int64
+	opts durableOptions
+
+	onEvict   EvictFunc[K, V]
+	replaying bool
+	pending   []byte // records of the operation in progress
+	writing   K      // the key of the Set or Put in progress
+	isWriting bool   // whether a Set or Put is in progress
+	err       error  // the first error writing or compacting the log
+}
+
+type durableOptions struct {
+	compactionSize int64
+	syncWrites     bool
+}
+
+// DurableOption configures OpenDurable.
+type DurableOption func(*durableOptions)
+
+// WithCompactionSize sets the size in bytes a write-ahead log grows to before
+// it is compacted into a snapshot. Zero or less means the log is only
+// compacted by Compact.
+func WithCompactionSize(size int64) DurableOption {
+	return func(o *durableOptions) {
+		o.compactionSize = size
+	}
+}
+
+// WithSyncWrites makes every write wait until the log is synced to disk, so it
+// also survives a crash of the machine rather than just of the process.
+func WithSyncWrites(sync bool) DurableOption {
+	return func(o *durableOptions) {
+		o.syncWrites = sync
+	}
+}
+
+// OpenDurable makes m durable, keeping its snapshot and write-ahead log in dir,
+// which is created if needed. m should be empty; it is filled from the last
+// snapshot and the log, and then keeps its own capacity, policy, TTL and
+// codecs (see SetCodec). The caller must not use m directly afterwards.
+//
+// A log that ends in a partly written or corrupted record, as a crash leaves
+// it, is replayed up to that record and truncated there.
+func OpenDurable[K comparable, V any](dir string, m *RingMapOf[K, V], opts ...DurableOption) (*DurableRingMap[K, V], error) {
+	d := &DurableRingMap[K, V]{
+		m:       m,
+		dir:     dir,
+		opts:    durableOptions{compactionSize: DefaultCompactionSize},
+		onEvict: m.onEvict,
+	}
+	for _, opt := range opts {
+		opt(&d.opts)
+	}
+	m.OnEvict(d.evicted)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := d.recover(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// recover loads the last snapshot, replays the log and opens it for writing.
+func (d *DurableRingMap[K, V]) recover() error {
+	d.replaying = true
+	defer func() { d.replaying = false }()
+
+	seq, err := d.lastSeq()
+	if err != nil {
+		return err
+	}
+	d.seq = seq
+	if seq > 0 {
+		if err := d.m.LoadFile(d.path("snapshot")); err != nil {
+			return err
+		}
+	}
+
+	// The log holds every eviction, so the bounds of the map are lifted while
+	// it is replayed and applied again afterwards.
+	capacity, maxWeight := d.m.capacity, d.m.maxWeight
+	d.m.capacity, d.m.maxWeight = Unbounded, Unbounded
+	size, err := d.replay()
+	d.m.capacity, d.m.maxWeight = capacity, maxWeight
+	if err != nil {
+		return err
+	}
+	d.m.evictFor(0, 0)
+	d.m.ResetStats()
+
+	d.log, err = os.OpenFile(d.path("wal"), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := d.log.Truncate(size); err != nil {
+		return err
+	}
+	if _, err := d.log.Seek(size, io.SeekStart); err != nil {
+		return err
+	}
+	d.size = size
+
+	d.removeStale()
+
+	return nil
+}
+
+// lastSeq returns the sequence number of the last snapshot, or zero if there
+// is none.
+func (d *DurableRingMap[K, V]) lastSeq() (uint64, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var last uint64
+	for _, entry := range entries {
+		if seq, ok := parseSeq(entry.Name(), "snapshot"); ok && seq > last {
+			last = seq
+		}
+	}
+
+	return last, nil
+}
+
+// parseSeq returns the sequence number in a file name like "wal.3".
+func parseSeq(name, kind string) (uint64, bool) {
+	rest, ok := strings.CutPrefix(name, kind+".")
+	if !ok {
+		return 0, false
+	}
+
+	seq, err := strconv.ParseUint(rest, 10, 64)
+	return seq, err == nil
+}
+
+// path returns the path of the current file of a kind, "snapshot" or "wal".
+func (d *DurableRingMap[K, V]) path(kind string) string {
+	return filepath.Join(d.dir, kind+"."+strconv.FormatUint(d.seq, 10))
+}
+
+// removeStale removes the files of earlier sequence numbers, and temporary
+// files left behind by a crash during compaction.
+func (d *DurableRingMap[K, V]) removeStale() {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		snapshot, isSnapshot := parseSeq(name, "snapshot")
+		wal, isWAL := parseSeq(name, "wal")
+		if (isSnapshot && snapshot < d.seq) || (isWAL && wal < d.seq) || strings.Contains(name, ".tmp") {
+			os.Remove(filepath.Join(d.dir, name))
+		}
+	}
+}
+
+// replay applies the records of the current log to the map. It returns the
+// size of the part of the log that could be replayed; anything after that is
+// a record that was only partly written, or corrupted.
+func (d *DurableRingMap[K, V]) replay() (int64, error) {
+	data, err := os.ReadFile(d.path("wal"))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	keys, values := d.m.codecs()
+	var good int64
+	for len(data) > 0 {
+		length, n := binary.Uvarint(data)
+		if n <= 0 || length > uint64(len(data)-n) || uint64(len(data)-n)-length < crc32.Size {
+			break
+		}
+		body := data[n : n+int(length)]
+		sum := data[n+int(length) : n+int(length)+crc32.Size]
+		if crc32.Checksum(body, snapshotTable) != binary.BigEndian.Uint32(sum) {
+			break
+		}
+		if err := d.apply(body, keys, values); err != nil {
+			if errors.Is(err, ErrInvalidSnapshot) {
+				break
+			}
+			return 0, err
+		}
+
+		size := n + int(length) + crc32.Size
+		data = data[size:]
+		good += int64(size)
+	}
+
+	return good, nil
+}
+
+// apply applies one record of the log to the map.
+func (d *DurableRingMap[K, V]) apply(body []byte, keys Codec[K], values Codec[V]) error {
+	if len(body) == 0 {
+		return fmt.Errorf("%w: empty log record", ErrInvalidSnapshot)
+	}
+
+	op := body[0]
+	dec := snapshotDecoder{data: body[1:]}
+	var expires int64
+	if op == walSet || op == walPut {
+		expires = dec.varint()
+	}
+	rawKey := dec.bytes()
+	var rawValue []byte
+	if op == walSet || op == walPut {
+		rawValue = dec.bytes()
+	}
+	if dec.err != nil {
+		return dec.err
+	}
+
+	var key K
+	if err := keys.Unmarshal(rawKey, &key); err != nil {
+		return fmt.Errorf("ringmap: decoding key: %w", err)
+	}
+
+	switch op {
+	case walSet, walPut:
+		var value V
+		if err := values.Unmarshal(rawValue, &value); err != nil {
+			return fmt.Errorf("ringmap: decoding value: %w", err)
+		}
+		var at time.Time
+		if expires != 0 {
+			at = time.Unix(0, expires)
+		}
+		if op == walSet {
+			d.m.set(key, value, at)
+		} else {
+			d.m.put(key, value, at)
+		}
+	case walDelete, walEvict:
+		d.m.Delete(key)
+	default:
+		return fmt.Errorf("%w: unknown log record %d", ErrInvalidSnapshot, op)
+	}
+
+	return nil
+}
+
+// evicted is the eviction callback of the map. It logs the entries that leave
+// the map on their own, for capacity or because they expired, and calls the
+// callback set with OnEvict. The old entry of the key being written isn't
+// logged, since the record of the write replaces it on replay, and a record
+// evicting the key after it would delete the new entry.
+func (d *DurableRingMap[K, V]) evicted(key K, value V, reason EvictReason) {
+	if d.replaying {
+		return
+	}
+
+	if (reason == EvictCapacity || reason == EvictExpired) && !(d.isWriting && key == d.writing) {
+		var zero V
+		record, err := d.encode(walEvict, key, zero, time.Time{})
+		if err != nil {
+			d.fail(err)
+		}
+		d.pending = append(d.pending, record...)
+	}
+	if d.onEvict != nil {
+		d.onEvict(key, value, reason)
+	}
+}
+
+// encode returns a log record. The value and expiry time are only written for
+// walSet and walPut.
+func (d *DurableRingMap[K, V]) encode(op byte, key K, value V, expires time.Time) ([]byte, error) {
+	keys, values := d.m.codecs()
+	rawKey, err := keys.Marshal(key)
+	if err != nil {
+		return nil, fmt.Errorf("ringmap: encoding key: %w", err)
+	}
+
+	body := []byte{op}
+	if op == walSet || op == walPut {
+		rawValue, err := values.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("ringmap: encoding value: %w", err)
+		}
+
+		var at int64
+		if !expires.IsZero() {
+			at = expires.UnixNano()
+		}
+		body = binary.AppendVarint(body, at)
+		body = appendBytes(body, rawKey)
+		body = appendBytes(body, rawValue)
+	} else {
+		body = appendBytes(body, rawKey)
+	}
+
+	record := appendBytes(nil, body)
+	return binary.BigEndian.AppendUint32(record, crc32.Checksum(body, snapshotTable)), nil
+}
+
+// appendBytes appends b to data, prefixed with its length.
+func appendBytes(data, b []byte) []byte {
+	data = binary.AppendUvarint(data, uint64(len(b)))
+	return append(data, b...)
+}
+
+// fail makes err the error of every following write, unless there already is
+// one.
+func (d *DurableRingMap[K, V]) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+// write logs a Set or a Put and applies it to the map with fn. The record of
+// the operation goes first, followed by the records of the entries it evicted,
+// all in a single write.
+func (d *DurableRingMap[K, V]) write(op byte, key K, value V, ttl time.Duration, fn func(key K, value V, expires time.Time) (bool, error)) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return false, d.err
+	}
+
+	expires := d.m.expiry(ttl)
+	record, err := d.encode(op, key, value, expires)
+	if err != nil {
+		return false, err
+	}
+
+	d.pending = append(d.pending[:0], record...)
+	d.writing, d.isWriting = key, true
+	added, err := fn(key, value, expires)
+	var zero K
+	d.writing, d.isWriting = zero, false
+	if err != nil {
+		// The map is unchanged, apart from what was evicted to make room.
+		d.pending = d.pending[len(record):]
+	}
+	if werr := d.flush(); werr != nil {
+		return false, werr
+	}
+
+	return added, err
+}
+
+// flush appends the pending records to the log, and compacts it if it has
+// grown too large. An error writing the log is returned by every following
+// write, since the map has changed without the log knowing. So is an error
+// compacting it, rather than letting the log grow without bounds.
+func (d *DurableRingMap[K, V]) flush() error {
+	if d.err == nil && len(d.pending) > 0 {
+		n, err := d.log.Write(d.pending)
+		d.size += int64(n)
+		if err == nil && d.opts.syncWrites {
+			err = d.log.Sync()
+		}
+		if err != nil {
+			d.fail(err)
+		}
+	}
+	d.pending = d.pending[:0]
+	if d.err != nil {
+		return d.err
+	}
+
+	if d.opts.compactionSize > 0 && d.size >= d.opts.compactionSize {
+		if err := d.compact(); err != nil {
+			d.fail(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compact writes a snapshot with the next sequence number and starts a new,
+// empty log for it.
+func (d *DurableRingMap[K, V]) compact() error {
+	next := strconv.FormatUint(d.seq+1, 10)
+	snapshot := filepath.Join(d.dir, "snapshot."+next)
+	if err := d.m.SaveFile(snapshot); err != nil {
+		return err
+	}
+
+	log, err := os.OpenFile(filepath.Join(d.dir, "wal."+next), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		// Without the new snapshot, the old one and the log still hold
+		// everything.
+		if rerr := os.Remove(snapshot); rerr != nil {
+			d.fail(rerr)
+		}
+		return err
+	}
+	syncDir(d.dir)
+
+	d.log.Close()
+	d.log = log
+	d.seq++
+	d.size = 0
+	d.removeStale()
+
+	return nil
+}
+
+// syncDir syncs a directory, so the files created or renamed in it survive a
+// crash. Not every platform supports it, so errors are ignored.
+func syncDir(dir string) {
+	if f, err := os.Open(dir); err == nil {
+		f.Sync()
+		f.Close()
+	}
+}
+
+// OnEvict sets the function that is called for every entry that leaves the
+// map. See RingMapOf.OnEvict. It isn't called while the map is replayed from the
+// log. fn is called while the lock is held and must not use d.
+func (d *DurableRingMap[K, V]) OnEvict(fn EvictFunc[K, V]) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.onEvict = fn
+}
+
+// Get returns the value for a key. See RingMapOf.Get.
+func (d *DurableRingMap[K, V]) Get(key K) (V, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.m.Get(key)
+}
+
+// Peek returns the value for a key without promoting it. See RingMapOf.Peek.
+func (d *DurableRingMap[K, V]) Peek(key K) (V, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.m.Peek(key)
+}
+
+// Set logs and sets a value for a key like RingMapOf.TrySet. It also returns an
+// error if the log can't be written.
+func (d *DurableRingMap[K, V]) Set(key K, value V) (bool, error) {
+	return d.write(walSet, key, value, d.defaultTTL(), d.m.set)
+}
+
+// Put logs and puts a value for a key like RingMapOf.TryPut. It also returns an
+// error if the log can't be written.
+func (d *DurableRingMap[K, V]) Put(key K, value V) (bool, error) {
+	return d.write(walPut, key, value, d.defaultTTL(), d.m.put)
+}
+
+// SetWithTTL is like Set, but the entry expires after ttl. See
+// RingMapOf.SetWithTTL.
+func (d *DurableRingMap[K, V]) SetWithTTL(key K, value V, ttl time.Duration) (bool, error) {
+	return d.write(walSet, key, value, ttl, d.m.set)
+}
+
+// PutWithTTL is like Put, but the entry expires after ttl. See
+// RingMapOf.PutWithTTL.
+func (d *DurableRingMap[K, V]) PutWithTTL(key K, value V, ttl time.Duration) (bool, error) {
+	return d.write(walPut, key, value, ttl, d.m.put)
+}
+
+func (d *DurableRingMap[K, V]) defaultTTL() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.m.ttl
+}
+
+// Delete logs and removes a key. It returns true if the key was removed, and
+// an error if the log can't be written.
+func (d *DurableRingMap[K, V]) Delete(key K) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return false, d.err
+	}
+	i, ok := d.m.items[key]
+	if !ok {
+		return false, nil
+	}
+	if d.m.isExpired(i, time.Time{}) {
+		// Removing the expired key logs it like any other expired entry.
+		d.pending = d.pending[:0]
+		d.m.Delete(key)
+		return false, d.flush()
+	}
+
+	var zero V
+	record, err := d.encode(walDelete, key, zero, time.Time{})
+	if err != nil {
+		return false, err
+	}
+	d.pending = append(d.pending[:0], record...)
+	d.m.Delete(key)
+
+	return true, d.flush()
+}
+
+// PurgeExpired logs and removes every expired entry, and returns how many were
+// removed. See RingMapOf.PurgeExpired.
+func (d *DurableRingMap[K, V]) PurgeExpired() (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	d.pending = d.pending[:0]
+	n := d.m.PurgeExpired()
+
+	return n, d.flush()
+}
+
+// Len returns the number of elements in the map. See RingMapOf.Len.
+func (d *DurableRingMap[K, V]) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.m.Len()
+}
+
+// Capacity returns the capacity of the map.
+func (d *DurableRingMap[K, V]) Capacity() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.m.Capacity()
+}
+
+// Keys returns a copy of the keys in order. See RingMapOf.Keys.
+func (d *DurableRingMap[K, V]) Keys() []K {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.m.Keys()
+}
+
+// Stats returns a snapshot of the counters of the map, which start at zero
+// when it's opened. See RingMapOf.Stats.
+func (d *DurableRingMap[K, V]) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.m.Stats()
+}
+
+// Compact writes a snapshot of the map and starts a new, empty log.
+func (d *DurableRingMap[K, V]) Compact() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return d.err
+	}
+
+	return d.compact()
+}
+
+// Sync commits the log to disk.
+func (d *DurableRingMap[K, V]) Sync() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return d.err
+	}
+
+	return d.log.Sync()
+}
+
+// Close syncs and closes the log. The map must not be used afterwards.
+func (d *DurableRingMap[K, V]) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	err := d.log.Sync()
+	if cerr := d.log.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
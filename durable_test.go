@@ -0,0 +1,300 @@
+package ringmap_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func openDurable(t *testing.T, dir string, m *ringmap.RingMapOf[string, int], opts ...ringmap.DurableOption) *ringmap.DurableRingMap[string, int] {
+	d, err := ringmap.OpenDurable(dir, m, opts...)
+	assert.NoError(t, err)
+
+	return d
+}
+
+// durableState returns the entries of d in order, as "key=value".
+func durableState(d *ringmap.DurableRingMap[string, int]) []string {
+	state := []string{}
+	for _, key := range d.Keys() {
+		value, _ := d.Peek(key)
+		state = append(state, key+"="+strconv.Itoa(value))
+	}
+
+	return state
+}
+
+func fileNames(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	return names
+}
+
+func TestDurableRingMap(t *testing.T) {
+	t.Run("Reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		d := openDurable(t, dir, ringmap.NewRingMapOf[string, int](ringMapCapacity))
+		d.Set("a", 1)
+		d.Set("b", 2)
+		d.Set("c", 3)
+		d.Put("a", 4)
+		deleted, err := d.Delete("b")
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+		assert.NoError(t, d.Close())
+
+		d = openDurable(t, dir, ringmap.NewRingMapOf[string, int](ringMapCapacity))
+		defer d.Close()
+		assert.Equal(t, []string{"c=3", "a=4"}, durableState(d))
+	})
+
+	t.Run("ReplaysEvictions", func(t *testing.T) {
+		dir := t.TempDir()
+		d := openDurable(t, dir, ringmap.NewLRURingMapOf[string, int](2))
+		d.Set("a", 1)
+		d.Set("b", 2)
+		d.Get("a")
+		d.Set("c", 3)
+		assert.Equal(t, []string{"a=1", "c=3"}, durableState(d))
+		assert.NoError(t, d.Close())
+
+		// The read of "a" isn't logged, but the eviction of "b" is.
+		d = openDurable(t, dir, ringmap.NewLRURingMapOf[string, int](2))
+		defer d.Close()
+		assert.Equal(t, []string{"a=1", "c=3"}, durableState(d))
+	})
+
+	t.Run("OnEvictSkipsReplay", func(t *testing.T) {
+		dir := t.TempDir()
+		d := openDurable(t, dir, ringmap.NewRingMapOf[string, int](1))
+		d.Set("a", 1)
+		d.Set("b", 2)
+		assert.NoError(t, d.Close())
+
+		m := ringmap.NewRingMapOf[string, int](1)
+		evictions := recordEvictions(m)
+		d = openDurable(t, dir, m)
+		defer d.Close()
+		assert.Empty(t, *evictions)
+
+		d.Set("c", 3)
+		assert.Equal(t, []eviction{{"b", 2, ringmap.EvictCapacity}}, *evictions)
+	})
+
+	t.Run("TTL", func(t *testing.T) {
+		dir := t.TempDir()
+		m, clock := newTTLMap(ringMapCapacity)
+		d := openDurable(t, dir, m)
+		d.SetWithTTL("a", 1, time.Minute)
+		d.Set("b", 2)
+		assert.NoError(t, d.Close())
+
+		m = ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.SetClock(clock)
+		d = openDurable(t, dir, m)
+		_, ok := d.Get("a")
+		assert.True(t, ok)
+		assert.NoError(t, d.Close())
+
+		clock.Advance(2 * time.Minute)
+		m = ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.SetClock(clock)
+		d = openDurable(t, dir, m)
+		defer d.Close()
+		_, ok = d.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, []string{"b=2"}, durableState(d))
+	})
+
+	t.Run("SetOverExpired", func(t *testing.T) {
+		dir := t.TempDir()
+		m, clock := newTTLMap(ringMapCapacity)
+		d := openDurable(t, dir, m)
+		d.SetWithTTL("a", 1, time.Second)
+		d.PutWithTTL("b", 2, time.Second)
+		clock.Advance(2 * time.Second)
+		d.Set("a", 3)
+		d.Put("b", 4)
+		assert.Equal(t, []string{"a=3", "b=4"}, durableState(d))
+		assert.NoError(t, d.Close())
+
+		m = ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.SetClock(clock)
+		d = openDurable(t, dir, m)
+		defer d.Close()
+		assert.Equal(t, []string{"a=3", "b=4"}, durableState(d))
+	})
+
+	t.Run("DeleteOfExpired", func(t *testing.T) {
+		dir := t.TempDir()
+		m, clock := newTTLMap(ringMapCapacity)
+		d := openDurable(t, dir, m)
+		d.SetWithTTL("a", 1, time.Second)
+		d.Set("b", 2)
+		clock.Advance(2 * time.Second)
+		ok, err := d.Delete("a")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.NoError(t, d.Close())
+
+		m = ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		d = openDurable(t, dir, m)
+		defer d.Close()
+		assert.Equal(t, []string{"b=2"}, durableState(d))
+	})
+
+	t.Run("Compaction", func(t *testing.T) {
+		dir := t.TempDir()
+		d := openDurable(t, dir, ringmap.NewRingMapOf[string, int](2), ringmap.WithCompactionSize(1))
+		d.Set("a", 1)
+		d.Set("b", 2)
+		d.Set("c", 3)
+		assert.Equal(t, []string{"snapshot.3", "wal.3"}, fileNames(t, dir))
+		assert.NoError(t, d.Close())
+
+		d = openDurable(t, dir, ringmap.NewRingMapOf[string, int](2), ringmap.WithCompactionSize(0))
+		assert.Equal(t, []string{"b=2", "c=3"}, durableState(d))
+		d.Set("d", 4)
+		assert.NoError(t, d.Close())
+
+		d = openDurable(t, dir, ringmap.NewRingMapOf[string, int](2))
+		defer d.Close()
+		assert.Equal(t, []string{"c=3", "d=4"}, durableState(d))
+		assert.Equal(t, []string{"snapshot.3", "wal.3"}, fileNames(t, dir))
+	})
+
+	t.Run("CompactionError", func(t *testing.T) {
+		dir := t.TempDir()
+		d := openDurable(t, dir, ringmap.NewRingMapOf[string, int](ringMapCapacity), ringmap.WithCompactionSize(1))
+		defer d.Close()
+
+		// The snapshot can't replace a directory that isn't empty.
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, "snapshot.1", "x"), 0o755))
+		_, err := d.Set("a", 1)
+		assert.Error(t, err)
+		_, err2 := d.Set("b", 2)
+		assert.Equal(t, err, err2)
+		assert.Equal(t, []string{"a=1"}, durableState(d))
+	})
+
+	t.Run("Compact", func(t *testing.T) {
+		dir := t.TempDir()
+		d := openDurable(t, dir, ringmap.NewRingMapOf[string, int](ringMapCapacity))
+		d.Set("a", 1)
+		assert.Equal(t, []string{"wal.0"}, fileNames(t, dir))
+		assert.NoError(t, d.Compact())
+		assert.Equal(t, []string{"snapshot.1", "wal.1"}, fileNames(t, dir))
+		assert.NoError(t, d.Close())
+
+		d = openDurable(t, dir, ringmap.NewRingMapOf[string, int](ringMapCapacity))
+		defer d.Close()
+		assert.Equal(t, []string{"a=1"}, durableState(d))
+	})
+
+	t.Run("RemovesStaleFiles", func(t *testing.T) {
+		dir := t.TempDir()
+		d := openDurable(t, dir, ringmap.NewRingMapOf[string, int](ringMapCapacity))
+		d.Set("a", 1)
+		assert.NoError(t, d.Compact())
+		assert.NoError(t, d.Close())
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "wal.0"), nil, 0o644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "snapshot.2.tmp"), nil, 0o644))
+
+		d = openDurable(t, dir, ringmap.NewRingMapOf[string, int](ringMapCapacity))
+		defer d.Close()
+		assert.Equal(t, []string{"snapshot.1", "wal.1"}, fileNames(t, dir))
+	})
+
+	t.Run("FailsAfterWriteError", func(t *testing.T) {
+		d := openDurable(t, t.TempDir(), ringmap.NewRingMapOf[string, int](ringMapCapacity))
+		assert.NoError(t, d.Close())
+
+		_, err := d.Set("a", 1)
+		assert.Error(t, err)
+		_, err2 := d.Delete("a")
+		assert.Equal(t, err, err2)
+		assert.Equal(t, err, d.Compact())
+	})
+}
+
+// TestDurableRingMap_Crash replays the log of a series of writes cut off at
+// every byte, as a crash could leave it, and checks that the recovered map
+// matches the map after the last write that made it to the log.
+func TestDurableRingMap_Crash(t *testing.T) {
+	dir := t.TempDir()
+	d := openDurable(t, dir, ringmap.NewRingMapOf[string, int](3), ringmap.WithCompactionSize(0))
+
+	writes := []func(){
+		func() { d.Set("a", 1) },
+		func() { d.Set("b", 2) },
+		func() { d.Set("c", 3) },
+		func() { d.Put("a", 4) },
+		func() { d.Set("d", 5) },
+		func() { d.Delete("c") },
+		func() { d.Set("e", 6) },
+		func() { d.Set("f", 7) },
+		func() { d.Set("b", 8) },
+	}
+	sizes := []int64{0}
+	states := [][]string{{}}
+	for _, write := range writes {
+		write()
+		info, err := os.Stat(filepath.Join(dir, "wal.0"))
+		assert.NoError(t, err)
+		sizes = append(sizes, info.Size())
+		states = append(states, durableState(d))
+	}
+	assert.NoError(t, d.Close())
+
+	log, err := os.ReadFile(filepath.Join(dir, "wal.0"))
+	assert.NoError(t, err)
+	assert.Equal(t, sizes[len(sizes)-1], int64(len(log)))
+
+	for k := range log {
+		crashed := filepath.Join(t.TempDir(), strconv.Itoa(k))
+		assert.NoError(t, os.MkdirAll(crashed, 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(crashed, "wal.0"), log[:k], 0o644))
+
+		d, err := ringmap.OpenDurable(crashed, ringmap.NewRingMapOf[string, int](3))
+		if !assert.NoError(t, err, "offset %d", k) {
+			continue
+		}
+		state := durableState(d)
+		assert.NoError(t, d.Close())
+
+		i := 0
+		for i+1 < len(sizes) && sizes[i+1] <= int64(k) {
+			i++
+		}
+		if int64(k) == sizes[i] {
+			assert.Equal(t, states[i], state, "offset %d", k)
+		} else if !assert.Contains(t, states[i:i+2], state, "offset %d", k) {
+			continue
+		}
+
+		// The torn record is gone, and the log takes new writes.
+		info, err := os.Stat(filepath.Join(crashed, "wal.0"))
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(k))
+
+		d = openDurable(t, crashed, ringmap.NewRingMapOf[string, int](3))
+		d.Set("z", 26)
+		want := durableState(d)
+		assert.NoError(t, d.Close())
+
+		d = openDurable(t, crashed, ringmap.NewRingMapOf[string, int](3))
+		assert.Equal(t, want, durableState(d), "offset %d", k)
+		assert.NoError(t, d.Close())
+	}
+}
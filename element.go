@@ -0,0 +1,49 @@
+package ringmap
+
+// Element is a copy of an entry of a RingMap, returned by the deprecated Front
+// and Back. Key and Value hold the key and the value of the entry when the
+// Element was created; writing to them doesn't change the map.
+//
+// Deprecated: Use Entry, which reads the map instead of a copy. Element is
+// only returned by Front and Back, and will be removed in the next release.
+type Element[K comparable, V any] struct {
+	// The key that corresponds to this element in the ring map.
+	Key K
+
+	// The value stored with this element.
+	Value V
+
+	m     *RingMapOf[K, V]
+	index int
+	gen   uint32
+}
+
+// element returns an Element for the slot at index i, or nil for none.
+func (m *RingMapOf[K, V]) element(i int) *Element[K, V] {
+	if i == none {
+		return nil
+	}
+
+	s := m.slots.at(i)
+	return &Element[K, V]{Key: s.key, Value: s.value, m: m, index: i, gen: s.gen}
+}
+
+// Next returns the next list element or nil. It also returns nil once the
+// element has left the map.
+func (e *Element[K, V]) Next() *Element[K, V] {
+	if !e.m.slots.live(e.index, e.gen) {
+		return nil
+	}
+
+	return e.m.element(e.m.slots.at(e.index).next)
+}
+
+// Prev returns the previous list element or nil. It also returns nil once the
+// element has left the map.
+func (e *Element[K, V]) Prev() *Element[K, V] {
+	if !e.m.slots.live(e.index, e.gen) {
+		return nil
+	}
+
+	return e.m.element(e.m.slots.at(e.index).prev)
+}
@@ -0,0 +1,115 @@
+package ringmap
+
+// Entry is a handle to an entry of a RingMap, used to walk the map in order.
+// The zero Entry is not valid; FrontEntry, BackEntry, Next and Prev return it
+// when there is no such entry:
+//
+//	for e := m.FrontEntry(); e.IsValid(); e = e.Next() {
+//		fmt.Println(e.Key(), e.Value())
+//	}
+//
+// An Entry stays usable after its entry leaves the map: Key and Value return
+// what the entry held, Next and Prev return the zero Entry, and the mutators do
+// nothing.
+type Entry[K comparable, V any] struct {
+	m     *RingMapOf[K, V]
+	index int
+	gen   uint32
+
+	// The key and value of the entry when the handle was made, for when it
+	// has left the map.
+	key   K
+	value V
+}
+
+// FrontEntry returns the first entry of the map, the one Front() returns.
+func (m *RingMapOf[K, V]) FrontEntry() Entry[K, V] {
+	return m.entry(m.slots.front)
+}
+
+// BackEntry returns the last entry of the map, the one Back() returns.
+func (m *RingMapOf[K, V]) BackEntry() Entry[K, V] {
+	return m.entry(m.slots.back)
+}
+
+// entry returns the Entry for the slot at index i, which may be none.
+func (m *RingMapOf[K, V]) entry(i int) Entry[K, V] {
+	if i == none {
+		return Entry[K, V]{}
+	}
+
+	s := m.slots.at(i)
+	return Entry[K, V]{m: m, index: i, gen: s.gen, key: s.key, value: s.value}
+}
+
+// IsValid returns true if e refers to an entry, even one that has since left
+// the map.
+func (e Entry[K, V]) IsValid() bool {
+	return e.m != nil
+}
+
+// Key returns the key of the entry.
+func (e Entry[K, V]) Key() K {
+	return e.key
+}
+
+// Value returns the value of the entry. Like Peek, it doesn't count as an
+// access for the eviction policy.
+func (e Entry[K, V]) Value() V {
+	if e.inMap() {
+		return e.m.slots.at(e.index).value
+	}
+
+	return e.value
+}
+
+// Next returns the entry after e, towards Back().
+func (e Entry[K, V]) Next() Entry[K, V] {
+	if !e.inMap() {
+		return Entry[K, V]{}
+	}
+
+	return e.m.entry(e.m.slots.at(e.index).next)
+}
+
+// Prev returns the entry before e, towards Front().
+func (e Entry[K, V]) Prev() Entry[K, V] {
+	if !e.inMap() {
+		return Entry[K, V]{}
+	}
+
+	return e.m.entry(e.m.slots.at(e.index).prev)
+}
+
+// inMap returns true if the entry is still in its map.
+func (e Entry[K, V]) inMap() bool {
+	return e.m != nil && e.m.slots.live(e.index, e.gen)
+}
+
+// SetValue replaces the value of the entry through the map, exactly like
+// m.Set(e.Key(), value) would: the eviction policy, the eviction callback, the
+// statistics, the default TTL and the maximum weight all see it. It returns
+// false if the entry has left the map, in which case nothing is set.
+//
+// A value that needs more room than the map has may move the entry to the
+// back. e then no longer refers to it; use BackEntry to find it.
+func (e Entry[K, V]) SetValue(value V) bool {
+	if !e.inMap() {
+		return false
+	}
+
+	_, err := e.m.set(e.key, value, e.m.expiry(e.m.ttl))
+	return err == nil
+}
+
+// Delete removes the entry from the map, like m.Delete(e.Key()). It returns
+// false if the entry had already left the map. Next and Prev have to be called
+// before Delete to keep walking the map.
+func (e Entry[K, V]) Delete() bool {
+	if !e.inMap() {
+		return false
+	}
+
+	e.m.remove(e.index, EvictDeleted)
+	return true
+}
@@ -0,0 +1,110 @@
+package ringmap_test
+
+import (
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntry(t *testing.T) {
+	t.Run("Walk", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+
+		var keys []string
+		var values []int
+		for e := m.FrontEntry(); e.IsValid(); e = e.Next() {
+			keys = append(keys, e.Key())
+			values = append(values, e.Value())
+		}
+		assert.Equal(t, []string{"a", "b", "c"}, keys)
+		assert.Equal(t, []int{1, 2, 3}, values)
+
+		keys = nil
+		for e := m.BackEntry(); e.IsValid(); e = e.Prev() {
+			keys = append(keys, e.Key())
+		}
+		assert.Equal(t, []string{"c", "b", "a"}, keys)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		assert.False(t, m.FrontEntry().IsValid())
+		assert.False(t, m.BackEntry().IsValid())
+
+		var e ringmap.Entry[string, int]
+		assert.False(t, e.IsValid())
+	})
+
+	t.Run("SetValue", func(t *testing.T) {
+		m := ringmap.NewLRURingMapOf[string, int](ringMapCapacity)
+		evictions := recordEvictions(m)
+		m.Set("a", 1)
+		m.Set("b", 2)
+
+		assert.True(t, m.FrontEntry().SetValue(10))
+		value, _ := m.Peek("a")
+		assert.Equal(t, 10, value)
+		assert.Equal(t, []string{"b", "a"}, m.Keys(), "goes through the policy")
+		assert.Equal(t, []eviction{{"a", 1, ringmap.EvictReplaced}}, *evictions)
+		assert.Equal(t, uint64(1), m.Stats().Updates)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		evictions := recordEvictions(m)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+
+		for e := m.FrontEntry(); e.IsValid(); {
+			next := e.Next()
+			if e.Value()%2 == 1 {
+				assert.True(t, e.Delete())
+			}
+			e = next
+		}
+		assert.Equal(t, []string{"b"}, m.Keys())
+		assert.Equal(t, []eviction{
+			{"a", 1, ringmap.EvictDeleted},
+			{"c", 3, ringmap.EvictDeleted},
+		}, *evictions)
+	})
+
+	t.Run("StaleEntry", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		e := m.FrontEntry()
+		m.Delete("a")
+
+		assert.True(t, e.IsValid())
+		assert.Equal(t, "a", e.Key())
+		assert.Equal(t, 1, e.Value())
+		assert.False(t, e.Next().IsValid())
+		assert.False(t, e.Delete())
+
+		// The key is back, but in a new entry that e doesn't refer to.
+		m.Set("a", 3)
+		assert.False(t, e.SetValue(4))
+		assert.False(t, e.Delete())
+		value, _ := m.Get("a")
+		assert.Equal(t, 3, value)
+	})
+
+	t.Run("ElementShim", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.Set("a", 1)
+		m.Set("b", 2)
+
+		el := m.Front()
+		assert.Equal(t, "a", el.Key)
+		assert.Equal(t, 1, el.Value)
+		assert.Equal(t, "b", el.Next().Key)
+		assert.Equal(t, m.FrontEntry().Key(), el.Key)
+		assert.Equal(t, m.BackEntry().Key(), m.Back().Key)
+	})
+}
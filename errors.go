@@ -0,0 +1,40 @@
+package ringmap
+
+import "errors"
+
+var (
+	// ErrInvalidCapacity is returned by New when the capacity is missing or
+	// negative. Use Unbounded for a map without a capacity.
+	ErrInvalidCapacity = errors.New("ringmap: invalid capacity")
+
+	// ErrInvalidTTL is returned by New when the default TTL is negative.
+	ErrInvalidTTL = errors.New("ringmap: invalid TTL")
+
+	// ErrInvalidWeight is returned by New when the maximum weight is negative.
+	// Use Unbounded for a map without a maximum weight.
+	ErrInvalidWeight = errors.New("ringmap: invalid weight")
+
+	// ErrInvalidOption is returned by New when an option is nil or doesn't match
+	// the key and value types of the map.
+	ErrInvalidOption = errors.New("ringmap: invalid option")
+
+	// ErrZeroCapacity is returned when a key is added to a map with zero
+	// capacity, which rejects every insert.
+	ErrZeroCapacity = errors.New("ringmap: map has zero capacity")
+
+	// ErrFull is returned when a key is added to a full map and its eviction
+	// policy has no victim.
+	ErrFull = errors.New("ringmap: map is full")
+
+	// ErrOversized is returned when an entry is heavier than the maximum
+	// weight of the map, so it could never fit.
+	ErrOversized = errors.New("ringmap: entry is heavier than the maximum weight")
+
+	// ErrDuplicateName is returned by Exporter.Register when another map is
+	// registered under the same name.
+	ErrDuplicateName = errors.New("ringmap: duplicate map name")
+
+	// ErrInvalidSnapshot is returned by ReadFrom when the snapshot is
+	// truncated, corrupted or of an unknown version.
+	ErrInvalidSnapshot = errors.New("ringmap: invalid snapshot")
+)
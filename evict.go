@@ -0,0 +1,39 @@
+package ringmap
+
+// EvictReason tells why an entry was removed from a map.
+type EvictReason int
+
+const (
+	// EvictCapacity means the entry was evicted to make room for a new key.
+	EvictCapacity EvictReason = iota
+
+	// EvictDeleted means the entry was removed with Delete.
+	EvictDeleted
+
+	// EvictReplaced means the value of the entry was replaced by Set or Put.
+	// The callback receives the old value.
+	EvictReplaced
+
+	// EvictExpired means the time to live of the entry had passed.
+	EvictExpired
+)
+
+// String returns the name of the reason.
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictDeleted:
+		return "deleted"
+	case EvictReplaced:
+		return "replaced"
+	case EvictExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictFunc is called with the key and value of an entry that was removed from
+// a map, and the reason it was removed.
+type EvictFunc[K comparable, V any] func(key K, value V, reason EvictReason)
@@ -0,0 +1,138 @@
+package ringmap_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+type eviction struct {
+	key    string
+	value  int
+	reason ringmap.EvictReason
+}
+
+func recordEvictions(m *ringmap.RingMapOf[string, int]) *[]eviction {
+	var evictions []eviction
+	m.OnEvict(func(key string, value int, reason ringmap.EvictReason) {
+		evictions = append(evictions, eviction{key, value, reason})
+	})
+
+	return &evictions
+}
+
+func TestOnEvict(t *testing.T) {
+	t.Run("Capacity", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](2)
+		evictions := recordEvictions(m)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		assert.Equal(t, []eviction{{"a", 1, ringmap.EvictCapacity}}, *evictions)
+	})
+
+	t.Run("Deleted", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		evictions := recordEvictions(m)
+		m.Set("a", 1)
+		m.Delete("a")
+		m.Delete("a")
+		assert.Equal(t, []eviction{{"a", 1, ringmap.EvictDeleted}}, *evictions)
+	})
+
+	t.Run("ReplacedBySet", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		evictions := recordEvictions(m)
+		m.Set("a", 1)
+		m.Set("a", 2)
+		assert.Equal(t, []eviction{{"a", 1, ringmap.EvictReplaced}}, *evictions)
+	})
+
+	t.Run("ReplacedByPut", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		evictions := recordEvictions(m)
+		m.Put("a", 1)
+		m.Put("a", 2)
+		assert.Equal(t, []eviction{{"a", 1, ringmap.EvictReplaced}}, *evictions)
+	})
+
+	t.Run("EntryIsGoneDuringCallback", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](1)
+		var present bool
+		m.OnEvict(func(key string, _ int, _ ringmap.EvictReason) {
+			_, present = m.Peek(key)
+		})
+		m.Set("a", 1)
+		m.Set("b", 2)
+		assert.False(t, present)
+	})
+
+	t.Run("NilRemovesCallback", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		evictions := recordEvictions(m)
+		m.OnEvict(nil)
+		m.Set("a", 1)
+		m.Delete("a")
+		assert.Empty(t, *evictions)
+	})
+
+	t.Run("ExactlyOncePerEntry", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		m := ringmap.NewLRURingMapOf[string, int](8)
+		removed := map[int]int{}
+		m.OnEvict(func(_ string, value int, _ ringmap.EvictReason) {
+			removed[value]++
+		})
+
+		keys := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"}
+		stored := 0
+		for i := 0; i < 10000; i++ {
+			key := keys[rng.Intn(len(keys))]
+			switch rng.Intn(4) {
+			case 0:
+				m.Set(key, i)
+				stored++
+			case 1:
+				if _, ok := m.Peek(key); ok {
+					m.Put(key, i)
+					stored++
+				}
+			case 2:
+				m.Delete(key)
+			case 3:
+				m.Get(key)
+			}
+		}
+
+		for _, key := range m.Keys() {
+			value, _ := m.Peek(key)
+			removed[value]++
+		}
+		assert.Len(t, removed, stored)
+		for value, count := range removed {
+			assert.Equal(t, 1, count, "value %d", value)
+		}
+	})
+}
+
+func TestEvictReason_String(t *testing.T) {
+	assert.Equal(t, "capacity", ringmap.EvictCapacity.String())
+	assert.Equal(t, "deleted", ringmap.EvictDeleted.String())
+	assert.Equal(t, "replaced", ringmap.EvictReplaced.String())
+	assert.Equal(t, "expired", ringmap.EvictExpired.String())
+	assert.Equal(t, "unknown", ringmap.EvictReason(-1).String())
+}
+
+func TestSyncRingMap_OnEvict(t *testing.T) {
+	m := ringmap.NewSyncRingMap[string, int](1)
+	var reasons []ringmap.EvictReason
+	m.OnEvict(func(_ string, _ int, reason ringmap.EvictReason) {
+		reasons = append(reasons, reason)
+	})
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.GetAndDelete("b")
+	assert.Equal(t, []ringmap.EvictReason{ringmap.EvictCapacity, ringmap.EvictDeleted}, reasons)
+}
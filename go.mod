@@ -1,6 +1,11 @@
 module github.com/prgsmall/ringmap
 
-go 1.12
+go 1.20
 
-require github.com/stretchr/testify v1.4.0
-require github.com/elliotchance/orderedmap v1.2.2
+require github.com/stretchr/testify v1.9.0
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,82 @@
+//go:build go1.23
+
+package ringmap
+
+import (
+	"iter"
+	"time"
+)
+
+// All returns an iterator over the keys and values of the map, from Front() to
+// Back(). Expired entries are skipped, and reading an entry doesn't count as an
+// access for the eviction policy.
+//
+// The loop body may delete the current entry, and set or delete other keys.
+// Keys added or moved during the loop may or may not be visited.
+func (m *RingMapOf[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.walk(true, func(i int) bool {
+			s := m.slots.at(i)
+			return yield(s.key, s.value)
+		})
+	}
+}
+
+// Backward returns an iterator over the keys and values of the map, from
+// Back() to Front(). See All.
+func (m *RingMapOf[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.walk(false, func(i int) bool {
+			s := m.slots.at(i)
+			return yield(s.key, s.value)
+		})
+	}
+}
+
+// KeysSeq returns an iterator over the keys of the map, from Front() to Back().
+// Unlike Keys, it doesn't copy the keys. See All.
+func (m *RingMapOf[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.walk(true, func(i int) bool {
+			return yield(m.slots.at(i).key)
+		})
+	}
+}
+
+// Values returns an iterator over the values of the map, from Front() to
+// Back(). See All.
+func (m *RingMapOf[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.walk(true, func(i int) bool {
+			return yield(m.slots.at(i).value)
+		})
+	}
+}
+
+// walk calls yield for the index of every live slot, from the front to the
+// back if forward is true and from the back to the front otherwise, until
+// yield returns false. yield may change the map: the walk moves on past an
+// entry that is moved or removed before it's visited, and ends at the entry
+// that was last when it started, or at the last new entry in a forward walk.
+func (m *RingMapOf[K, V]) walk(forward bool, yield func(i int) bool) {
+	var now time.Time
+	if m.expiring > 0 {
+		now = m.clock.Now()
+	}
+
+	c := m.slots.track(forward)
+	defer m.slots.untrack(c)
+
+	for c.next != none {
+		i := c.next
+		if i == c.last {
+			c.next = none
+		} else {
+			c.next = m.slots.step(i, forward)
+		}
+
+		if !m.isExpired(i, now) && !yield(i) {
+			return
+		}
+	}
+}
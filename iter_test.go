@@ -0,0 +1,246 @@
+//go:build go1.23
+
+package ringmap_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIterMap(keys ...string) *ringmap.RingMapOf[string, int] {
+	m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+	for i, key := range keys {
+		m.Set(key, i)
+	}
+
+	return m
+}
+
+func TestIterators(t *testing.T) {
+	t.Run("All", func(t *testing.T) {
+		m := newIterMap("a", "b", "c")
+		var keys []string
+		var values []int
+		for key, value := range m.All() {
+			keys = append(keys, key)
+			values = append(values, value)
+		}
+		assert.Equal(t, []string{"a", "b", "c"}, keys)
+		assert.Equal(t, []int{0, 1, 2}, values)
+	})
+
+	t.Run("Backward", func(t *testing.T) {
+		m := newIterMap("a", "b", "c")
+		var keys []string
+		for key := range m.Backward() {
+			keys = append(keys, key)
+		}
+		assert.Equal(t, []string{"c", "b", "a"}, keys)
+	})
+
+	t.Run("KeysSeqAndValues", func(t *testing.T) {
+		m := newIterMap("a", "b", "c")
+		var keys []string
+		for key := range m.KeysSeq() {
+			keys = append(keys, key)
+		}
+		assert.Equal(t, m.Keys(), keys)
+
+		var values []int
+		for value := range m.Values() {
+			values = append(values, value)
+		}
+		assert.Equal(t, []int{0, 1, 2}, values)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		m := newIterMap()
+		for range m.All() {
+			t.Fatal("iterated over an empty map")
+		}
+		for range m.Backward() {
+			t.Fatal("iterated over an empty map")
+		}
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		m := newIterMap("a", "b", "c")
+		var keys []string
+		for key := range m.KeysSeq() {
+			keys = append(keys, key)
+			if key == "b" {
+				break
+			}
+		}
+		assert.Equal(t, []string{"a", "b"}, keys)
+	})
+
+	t.Run("DeleteCurrent", func(t *testing.T) {
+		m := newIterMap("a", "b", "c", "d")
+		var keys []string
+		for key := range m.All() {
+			keys = append(keys, key)
+			m.Delete(key)
+		}
+		assert.Equal(t, []string{"a", "b", "c", "d"}, keys)
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("DeleteCurrentBackward", func(t *testing.T) {
+		m := newIterMap("a", "b", "c", "d")
+		var keys []string
+		for key, value := range m.Backward() {
+			keys = append(keys, key)
+			if value%2 == 0 {
+				m.Delete(key)
+			}
+		}
+		assert.Equal(t, []string{"d", "c", "b", "a"}, keys)
+		assert.Equal(t, []string{"b", "d"}, m.Keys())
+	})
+
+	t.Run("DeleteNext", func(t *testing.T) {
+		m := newIterMap("a", "b", "c", "d")
+		var keys []string
+		for key := range m.KeysSeq() {
+			keys = append(keys, key)
+			if key == "a" {
+				m.Delete("b")
+			}
+		}
+		assert.Equal(t, []string{"a", "c", "d"}, keys)
+	})
+
+	t.Run("DeleteCurrentAndNext", func(t *testing.T) {
+		m := newIterMap("a", "b", "c", "d", "e")
+		var keys []string
+		for key := range m.KeysSeq() {
+			keys = append(keys, key)
+			if key == "b" {
+				m.Delete("b")
+				m.Delete("c")
+			}
+		}
+		assert.Equal(t, []string{"a", "b", "d", "e"}, keys)
+	})
+
+	t.Run("MoveCurrent", func(t *testing.T) {
+		m := ringmap.NewLRURingMapOf[string, int](ringMapCapacity)
+		for i, key := range []string{"a", "b", "c", "d", "e"} {
+			m.Set(key, i)
+		}
+		var keys []string
+		for key := range m.KeysSeq() {
+			keys = append(keys, key)
+			m.Get(key) // moves the current entry to the back
+		}
+		assert.Equal(t, []string{"a", "b", "c", "d", "e"}, keys)
+	})
+
+	t.Run("SetDuringLoop", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](3)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		var keys []string
+		for key := range m.KeysSeq() {
+			keys = append(keys, key)
+			if key == "a" {
+				m.Set("d", 4) // evicts "a", the current entry
+			}
+		}
+		assert.Equal(t, []string{"a", "b", "c", "d"}, keys)
+	})
+
+	t.Run("SkipsExpired", func(t *testing.T) {
+		m, clock := newTTLMap(ringMapCapacity)
+		m.Set("a", 1)
+		m.SetWithTTL("b", 2, time.Minute)
+		m.Set("c", 3)
+		clock.Advance(time.Minute)
+
+		var keys []string
+		for key := range m.All() {
+			keys = append(keys, key)
+		}
+		assert.Equal(t, []string{"a", "c"}, keys)
+	})
+
+	t.Run("DoesntPromote", func(t *testing.T) {
+		m := ringmap.NewLRURingMapOf[string, int](ringMapCapacity)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		for range m.All() {
+		}
+		assert.Equal(t, []string{"a", "b"}, m.Keys())
+	})
+}
+
+func benchmarkRingMap_All(multiplier int) func(b *testing.B) {
+	m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
+	for i := 0; i < 1000*multiplier; i++ {
+		m.Set(i, true)
+	}
+
+	return func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, v := range m.All() {
+				nothing(v)
+			}
+		}
+	}
+}
+
+func BenchmarkRingMap_All(b *testing.B) {
+	benchmarkRingMap_All(1)(b)
+}
+
+func BenchmarkRingMap_Backward(b *testing.B) {
+	m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, true)
+	}
+
+	for i := 0; i < b.N; i++ {
+		for _, v := range m.Backward() {
+			nothing(v)
+		}
+	}
+}
+
+func BenchmarkRingMap_KeysSeq(b *testing.B) {
+	m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, true)
+	}
+
+	for i := 0; i < b.N; i++ {
+		for key := range m.KeysSeq() {
+			nothing(key)
+		}
+	}
+}
+
+func BenchmarkRingMap_Values(b *testing.B) {
+	m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, true)
+	}
+
+	for i := 0; i < b.N; i++ {
+		for v := range m.Values() {
+			nothing(v)
+		}
+	}
+}
+
+func BenchmarkIterators(b *testing.B) {
+	b.Run("BenchmarkRingMap_Iterate", BenchmarkRingMap_Iterate)
+	b.Run("BenchmarkRingMap_All", BenchmarkRingMap_All)
+	b.Run("BenchmarkRingMap_Backward", BenchmarkRingMap_Backward)
+	b.Run("BenchmarkRingMap_KeysSeq", BenchmarkRingMap_KeysSeq)
+	b.Run("BenchmarkRingMap_Values", BenchmarkRingMap_Values)
+}
@@ -0,0 +1,206 @@
+package ringmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// jsonPair is how an entry of a map without string keys is encoded.
+type jsonPair[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// hasStringKeys returns true if the keys of type K are strings, including named
+// string types.
+func hasStringKeys[K comparable]() bool {
+	return reflect.TypeOf((*K)(nil)).Elem().Kind() == reflect.String
+}
+
+// MarshalJSON encodes the live entries of the map from Front() to Back(). A map
+// with string keys is encoded as an object whose members are in that order:
+//
+//	{"b":2,"a":1}
+//
+// Other maps, including ones created with NewRingMap, are encoded as an array
+// of key and value pairs:
+//
+//	[{"key":2,"value":"b"},{"key":1,"value":"a"}]
+func (m *RingMapOf[K, V]) MarshalJSON() ([]byte, error) {
+	stringKeys := hasStringKeys[K]()
+
+	var buf bytes.Buffer
+	if stringKeys {
+		buf.WriteByte('{')
+	} else {
+		buf.WriteByte('[')
+	}
+
+	first := true
+	for i := m.slots.front; m.items != nil && i != none; i = m.slots.at(i).next {
+		if m.isExpired(i, time.Time{}) {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		s := m.slots.at(i)
+		if !stringKeys {
+			pair, err := json.Marshal(jsonPair[K, V]{s.key, s.value})
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(pair)
+			continue
+		}
+
+		key, err := json.Marshal(reflect.ValueOf(s.key).String())
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(s.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	if stringKeys {
+		buf.WriteByte('}')
+	} else {
+		buf.WriteByte(']')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON replaces the entries of the map with the ones encoded in data,
+// in the format written by MarshalJSON; an array of pairs is accepted for
+// string keys too. The entries are added in order with Set, so a map with a
+// smaller capacity evicts entries like Set does and ends up with the last ones,
+// and entries that Set can't add are left out. The entries that were in the map
+// are deleted first, and reported to the eviction callback as EvictDeleted.
+//
+// If data can't be decoded, UnmarshalJSON returns an error and leaves the map
+// unchanged. Decoding into a zero RingMapOf makes it an unbounded FIFO map.
+func (m *RingMapOf[K, V]) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	pairs, err := unmarshalPairs[K, V](data)
+	if err != nil {
+		return err
+	}
+
+	if m.items == nil {
+		m.init(Unbounded, NewFIFOPolicy[K]())
+	}
+	m.deleteAll()
+	for _, pair := range pairs {
+		m.Set(pair.Key, pair.Value)
+	}
+
+	return nil
+}
+
+// unmarshalPairs decodes the entries in data, which is either an object or an
+// array of pairs, in order.
+func unmarshalPairs[K comparable, V any](data []byte) ([]jsonPair[K, V], error) {
+	var pairs []jsonPair[K, V]
+	if len(data) == 0 || data[0] != '{' {
+		if err := json.Unmarshal(data, &pairs); err != nil {
+			return nil, err
+		}
+
+		return pairs, nil
+	}
+
+	if !hasStringKeys[K]() {
+		return nil, fmt.Errorf("ringmap: cannot unmarshal a JSON object into a map with %s keys", typeName[K]())
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var pair jsonPair[K, V]
+		reflect.ValueOf(&pair.Key).Elem().SetString(token.(string))
+		if err := dec.Decode(&pair.Value); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
+// deleteAll deletes every entry of the map.
+func (m *RingMapOf[K, V]) deleteAll() {
+	for m.slots.front != none {
+		m.remove(m.slots.front, EvictDeleted)
+	}
+}
+
+// MarshalJSON encodes the map like RingMapOf.MarshalJSON. The zero RingMap
+// encodes as an empty map.
+func (m RingMap) MarshalJSON() ([]byte, error) {
+	if m.RingMapOf == nil {
+		return new(RingMapOf[interface{}, interface{}]).MarshalJSON()
+	}
+
+	return m.RingMapOf.MarshalJSON()
+}
+
+// UnmarshalJSON replaces the entries of the map like RingMapOf.UnmarshalJSON.
+// Decoding into the zero RingMap, for example a struct field, makes it an
+// unbounded FIFO map.
+func (m *RingMap) UnmarshalJSON(data []byte) error {
+	if m.RingMapOf == nil {
+		m.RingMapOf = NewRingMapOf[interface{}, interface{}](Unbounded)
+	}
+
+	return m.RingMapOf.UnmarshalJSON(data)
+}
+
+// MarshalJSON encodes the map under the read lock. See RingMapOf.MarshalJSON.
+func (s *SyncRingMap[K, V]) MarshalJSON() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.m == nil {
+		return new(RingMapOf[K, V]).MarshalJSON()
+	}
+
+	return s.m.MarshalJSON()
+}
+
+// UnmarshalJSON replaces the entries of the map under the write lock. See
+// RingMapOf.UnmarshalJSON.
+func (s *SyncRingMap[K, V]) UnmarshalJSON(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.m == nil {
+		s.m = NewRingMapOf[K, V](Unbounded)
+	}
+
+	return s.m.UnmarshalJSON(data)
+}
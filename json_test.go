@@ -0,0 +1,203 @@
+package ringmap_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonPoint struct {
+	X, Y int
+}
+
+func TestJSON(t *testing.T) {
+	t.Run("StringKeysAsObject", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.Set("b", 2)
+		m.Set("a", 1)
+		m.Set("c", 3)
+
+		data, err := json.Marshal(m)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"b":2,"a":1,"c":3}`, string(data))
+	})
+
+	t.Run("NamedStringKeys", func(t *testing.T) {
+		type name string
+		m := ringmap.NewRingMapOf[name, bool](ringMapCapacity)
+		m.Set("z\"", true)
+
+		data, err := json.Marshal(m)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"z\"":true}`, string(data))
+
+		n := ringmap.NewRingMapOf[name, bool](ringMapCapacity)
+		assert.NoError(t, json.Unmarshal(data, n))
+		assert.Equal(t, []name{"z\""}, n.Keys())
+	})
+
+	t.Run("OtherKeysAsPairs", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, jsonPoint](ringMapCapacity)
+		m.Set(2, jsonPoint{1, 2})
+		m.Set(1, jsonPoint{3, 4})
+
+		data, err := json.Marshal(m)
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"key":2,"value":{"X":1,"Y":2}},{"key":1,"value":{"X":3,"Y":4}}]`, string(data))
+	})
+
+	t.Run("Untyped", func(t *testing.T) {
+		m := ringmap.NewRingMap(ringMapCapacity)
+		m.Set("a", 1)
+		m.Set(2, "b")
+
+		data, err := json.Marshal(m)
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"key":"a","value":1},{"key":2,"value":"b"}]`, string(data))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		data, err := json.Marshal(ringmap.NewRingMapOf[string, int](ringMapCapacity))
+		assert.NoError(t, err)
+		assert.Equal(t, `{}`, string(data))
+
+		data, err = json.Marshal(ringmap.NewRingMapOf[int, int](ringMapCapacity))
+		assert.NoError(t, err)
+		assert.Equal(t, `[]`, string(data))
+	})
+
+	t.Run("SkipsExpired", func(t *testing.T) {
+		m, clock := newTTLMap(ringMapCapacity)
+		m.Set("a", 1)
+		m.SetWithTTL("b", 2, time.Minute)
+		clock.Advance(time.Minute)
+
+		data, err := json.Marshal(m)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, string(data))
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		for _, key := range []string{"z", "a", "m", "b"} {
+			m.Set(key, len(m.Keys()))
+		}
+
+		data, err := json.Marshal(m)
+		assert.NoError(t, err)
+		n := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		assert.NoError(t, json.Unmarshal(data, n))
+		assert.Equal(t, m.Keys(), n.Keys())
+		for _, key := range m.Keys() {
+			want, _ := m.Get(key)
+			got, _ := n.Get(key)
+			assert.Equal(t, want, got)
+		}
+	})
+
+	t.Run("RoundTripPairs", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, jsonPoint](ringMapCapacity)
+		for i := 5; i > 0; i-- {
+			m.Set(i, jsonPoint{i, -i})
+		}
+
+		data, err := json.Marshal(m)
+		assert.NoError(t, err)
+		n := ringmap.NewRingMapOf[int, jsonPoint](ringMapCapacity)
+		assert.NoError(t, json.Unmarshal(data, n))
+		assert.Equal(t, []int{5, 4, 3, 2, 1}, n.Keys())
+		value, _ := n.Get(3)
+		assert.Equal(t, jsonPoint{3, -3}, value)
+	})
+
+	t.Run("TruncatesToCapacity", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](2)
+		evictions := recordEvictions(m)
+		assert.NoError(t, json.Unmarshal([]byte(`{"a":1,"b":2,"c":3}`), m))
+		assert.Equal(t, []string{"b", "c"}, m.Keys())
+		assert.Equal(t, []eviction{{"a", 1, ringmap.EvictCapacity}}, *evictions)
+	})
+
+	t.Run("ReplacesEntries", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.Set("old", 0)
+		evictions := recordEvictions(m)
+
+		assert.NoError(t, json.Unmarshal([]byte(`{"b":2,"a":1}`), m))
+		assert.Equal(t, []string{"b", "a"}, m.Keys())
+		assert.Equal(t, []eviction{{"old", 0, ringmap.EvictDeleted}}, *evictions)
+	})
+
+	t.Run("PairsForStringKeys", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		assert.NoError(t, json.Unmarshal([]byte(`[{"key":"b","value":2},{"key":"a","value":1}]`), m))
+		assert.Equal(t, []string{"b", "a"}, m.Keys())
+	})
+
+	t.Run("ObjectForOtherKeys", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, int](ringMapCapacity)
+		assert.Error(t, json.Unmarshal([]byte(`{"1":1}`), m))
+	})
+
+	t.Run("InvalidLeavesMapUnchanged", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.Set("a", 1)
+		assert.Error(t, json.Unmarshal([]byte(`{"b":"not an int"}`), m))
+		assert.Error(t, m.UnmarshalJSON([]byte(`{"b":2`)))
+		assert.Equal(t, []string{"a"}, m.Keys())
+	})
+
+	t.Run("Null", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.Set("a", 1)
+		assert.NoError(t, json.Unmarshal([]byte(`null`), m))
+		assert.Equal(t, []string{"a"}, m.Keys())
+	})
+
+	t.Run("ZeroMap", func(t *testing.T) {
+		var m ringmap.RingMapOf[string, int]
+		data, err := json.Marshal(&m)
+		assert.NoError(t, err)
+		assert.Equal(t, `{}`, string(data))
+
+		assert.NoError(t, json.Unmarshal([]byte(`{"a":1,"b":2}`), &m))
+		assert.Equal(t, []string{"a", "b"}, m.Keys())
+		assert.Equal(t, ringmap.Unbounded, m.Capacity())
+	})
+
+	t.Run("UntypedStructField", func(t *testing.T) {
+		type dump struct {
+			Name   string
+			Recent ringmap.RingMap
+		}
+		var empty dump
+		data, err := json.Marshal(empty)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"Name":"","Recent":[]}`, string(data))
+
+		in := dump{Name: "recent", Recent: *ringmap.NewRingMap(ringMapCapacity)}
+		in.Recent.Set("a", 1.5)
+		in.Recent.Set(true, "b")
+		data, err = json.Marshal(in)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"Name":"recent","Recent":[{"key":"a","value":1.5},{"key":true,"value":"b"}]}`, string(data))
+
+		var out dump
+		assert.NoError(t, json.Unmarshal(data, &out))
+		assert.Equal(t, "recent", out.Name)
+		assert.Equal(t, []interface{}{"a", true}, out.Recent.Keys())
+		assert.Equal(t, "b", out.Recent.GetOrDefault(true, nil))
+	})
+
+	t.Run("SyncRingMap", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[string, int](2)
+		assert.NoError(t, json.Unmarshal([]byte(`{"a":1,"b":2,"c":3}`), m))
+
+		data, err := json.Marshal(m)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"b":2,"c":3}`, string(data))
+	})
+}
@@ -0,0 +1,49 @@
+package ringmap
+
+// keyList is a doubly linked list of keys that a policy keeps in its own
+// order, usually from least to most recently used.
+type keyList[K comparable] struct {
+	head, tail *keyEntry[K]
+	len        int
+}
+
+type keyEntry[K comparable] struct {
+	key        K
+	list       *keyList[K]
+	prev, next *keyEntry[K]
+}
+
+// push adds e at the back of the list.
+func (l *keyList[K]) push(e *keyEntry[K]) {
+	e.list = l
+	e.prev, e.next = l.tail, nil
+	if l.tail != nil {
+		l.tail.next = e
+	} else {
+		l.head = e
+	}
+	l.tail = e
+	l.len++
+}
+
+// remove takes e out of the list.
+func (l *keyList[K]) remove(e *keyEntry[K]) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		l.head = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		l.tail = e.prev
+	}
+	e.list, e.prev, e.next = nil, nil, nil
+	l.len--
+}
+
+// moveTo moves e from its list to the back of l.
+func (e *keyEntry[K]) moveTo(l *keyList[K]) {
+	e.list.remove(e)
+	l.push(e)
+}
@@ -0,0 +1,248 @@
+package ringmap
+
+import "container/heap"
+
+// LFUPolicy evicts the least frequently used element. Every insert, read with
+// Get or GetOrDefault and replacement with Set counts as a use. Of the keys
+// with the lowest frequency, the one that was inserted first is evicted.
+//
+// Frequencies are halved periodically, so keys that were hot once but aren't
+// used anymore age out instead of staying forever. See SetDecayPeriod.
+//
+// The keys are kept in buckets by frequency, each a heap ordered by insertion,
+// so every operation takes time logarithmic in the number of keys that share a
+// frequency, apart from the decay, which takes time linear in the number of
+// keys once per period.
+type LFUPolicy[K comparable] struct {
+	o       Ordering[K]
+	entries map[K]*lfuEntry[K]
+	min     *lfuBucket[K] // the bucket with the lowest frequency
+	inserts uint64
+	period  int
+	uses    int
+}
+
+// lfuBucket holds the keys with the same frequency. The buckets form a list
+// sorted by frequency.
+type lfuBucket[K comparable] struct {
+	freq       uint64
+	entries    lfuHeap[K]
+	prev, next *lfuBucket[K]
+}
+
+type lfuEntry[K comparable] struct {
+	key    K
+	seq    uint64 // the order of insertion
+	bucket *lfuBucket[K]
+	index  int // the index in the heap of the bucket
+}
+
+// lfuHeap is a heap of entries with the first inserted one on top. It
+// implements heap.Interface.
+type lfuHeap[K comparable] []*lfuEntry[K]
+
+func (h lfuHeap[K]) Len() int           { return len(h) }
+func (h lfuHeap[K]) Less(i, j int) bool { return h[i].seq < h[j].seq }
+
+func (h lfuHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap[K]) Push(x interface{}) {
+	e := x.(*lfuEntry[K])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lfuHeap[K]) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+
+	return e
+}
+
+// NewLFUPolicy creates a new LFU eviction policy.
+func NewLFUPolicy[K comparable]() *LFUPolicy[K] {
+	return &LFUPolicy[K]{entries: make(map[K]*lfuEntry[K])}
+}
+
+// NewLFURingMapOf creates a new ordered map in LFU mode with a maximum size
+// that holds keys of type K and values of type V.
+func NewLFURingMapOf[K comparable, V any](capacity int) *RingMapOf[K, V] {
+	return NewRingMapWithPolicy[K, V](capacity, NewLFUPolicy[K]())
+}
+
+// SetDecayPeriod sets the number of uses after which all frequencies are
+// halved. Zero, the default, means ten times the capacity of the map, or never
+// for an unbounded map. A negative period turns the decay off.
+func (p *LFUPolicy[K]) SetDecayPeriod(period int) {
+	p.period = period
+}
+
+// Frequency returns the current use count of a key, or zero for a key that
+// isn't in the map.
+func (p *LFUPolicy[K]) Frequency(key K) uint64 {
+	if e, ok := p.entries[key]; ok {
+		return e.bucket.freq
+	}
+
+	return 0
+}
+
+// Attach implements EvictionPolicy.
+func (p *LFUPolicy[K]) Attach(o Ordering[K]) {
+	p.o = o
+}
+
+// OnInsert implements EvictionPolicy. The key starts with a frequency of one.
+func (p *LFUPolicy[K]) OnInsert(key K) {
+	b := p.min
+	if b == nil || b.freq != 1 {
+		b = &lfuBucket[K]{freq: 1, next: p.min}
+		if p.min != nil {
+			p.min.prev = b
+		}
+		p.min = b
+	}
+
+	e := &lfuEntry[K]{key: key, seq: p.inserts}
+	p.inserts++
+	p.entries[key] = e
+	b.push(e)
+	p.use()
+}
+
+// OnAccess implements EvictionPolicy. It increments the frequency of key.
+func (p *LFUPolicy[K]) OnAccess(key K) {
+	p.increment(key)
+}
+
+// OnUpdate implements EvictionPolicy. It increments the frequency of key.
+func (p *LFUPolicy[K]) OnUpdate(key K) {
+	p.increment(key)
+}
+
+// OnDelete implements EvictionPolicy.
+func (p *LFUPolicy[K]) OnDelete(key K) {
+	e, ok := p.entries[key]
+	if !ok {
+		return
+	}
+
+	delete(p.entries, key)
+	p.unlink(e)
+}
+
+// Victim implements EvictionPolicy. It returns the first inserted key of those
+// with the lowest frequency.
+func (p *LFUPolicy[K]) Victim() (K, bool) {
+	if p.min == nil {
+		var zero K
+		return zero, false
+	}
+
+	return p.min.entries[0].key, true
+}
+
+// increment moves key to the bucket of the next frequency, creating it if
+// needed.
+func (p *LFUPolicy[K]) increment(key K) {
+	e, ok := p.entries[key]
+	if !ok {
+		return
+	}
+
+	b := e.bucket
+	next := b.next
+	if next == nil || next.freq != b.freq+1 {
+		next = &lfuBucket[K]{freq: b.freq + 1, prev: b, next: b.next}
+		if b.next != nil {
+			b.next.prev = next
+		}
+		b.next = next
+	}
+
+	p.unlink(e)
+	next.push(e)
+	p.use()
+}
+
+// unlink removes e from its bucket, and the bucket from the list if it's left
+// empty.
+func (p *LFUPolicy[K]) unlink(e *lfuEntry[K]) {
+	b := e.bucket
+	heap.Remove(&b.entries, e.index)
+	e.bucket = nil
+
+	if len(b.entries) > 0 {
+		return
+	}
+	if b.prev != nil {
+		b.prev.next = b.next
+	} else {
+		p.min = b.next
+	}
+	if b.next != nil {
+		b.next.prev = b.prev
+	}
+}
+
+// push adds e to the bucket.
+func (b *lfuBucket[K]) push(e *lfuEntry[K]) {
+	e.bucket = b
+	heap.Push(&b.entries, e)
+}
+
+// use counts a use, and decays the frequencies at the end of a period.
+func (p *LFUPolicy[K]) use() {
+	period := p.period
+	if period == 0 {
+		period = 10 * p.o.Capacity()
+	}
+	if period <= 0 {
+		return
+	}
+
+	p.uses++
+	if p.uses >= period {
+		p.uses = 0
+		p.decay()
+	}
+}
+
+// decay halves every frequency, down to one. Buckets that end up with the same
+// frequency are merged.
+func (p *LFUPolicy[K]) decay() {
+	var head, tail *lfuBucket[K]
+	for b := p.min; b != nil; {
+		next := b.next
+		freq := b.freq / 2
+		if freq == 0 {
+			freq = 1
+		}
+
+		if tail != nil && tail.freq == freq {
+			// Move the keys over to the bucket that has the frequency already.
+			tail.entries = append(tail.entries, b.entries...)
+			for i, e := range tail.entries {
+				e.bucket, e.index = tail, i
+			}
+			heap.Init(&tail.entries)
+		} else {
+			b.freq = freq
+			b.prev, b.next = tail, nil
+			if tail != nil {
+				tail.next = b
+			} else {
+				head = b
+			}
+			tail = b
+		}
+		b = next
+	}
+	p.min = head
+}
@@ -0,0 +1,151 @@
+package ringmap_test
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+// replay runs a trace of keys through m like a read-through cache: a key that
+// misses is set. It returns the hit ratio.
+func replay(m *ringmap.RingMapOf[string, int], trace []string) float64 {
+	m.ResetStats()
+	for _, key := range trace {
+		if _, ok := m.Get(key); !ok {
+			m.Set(key, 0)
+		}
+	}
+
+	return m.Stats().HitRatio()
+}
+
+// hotsetTrace returns n keys. Every other one is picked at random from a set of
+// hot keys, and the rest are scanned keys that are only used once.
+func hotsetTrace(r *rand.Rand, n, hot int, prefix string) []string {
+	trace := make([]string, n)
+	for i := range trace {
+		if i%2 == 0 {
+			trace[i] = prefix + "hot" + strconv.Itoa(r.Intn(hot))
+		} else {
+			trace[i] = prefix + "scan" + strconv.Itoa(i)
+		}
+	}
+
+	return trace
+}
+
+func TestLFUPolicy(t *testing.T) {
+	t.Run("EvictsLeastFrequent", func(t *testing.T) {
+		m := ringmap.NewLFURingMapOf[string, int](3)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		m.Get("a")
+		m.Get("a")
+		m.Get("c")
+		m.Set("d", 4)
+		assert.Equal(t, []string{"a", "c", "d"}, m.Keys())
+	})
+
+	t.Run("TiesByInsertionOrder", func(t *testing.T) {
+		m := ringmap.NewLFURingMapOf[string, int](3)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		m.Set("d", 4)
+		m.Set("e", 5)
+		assert.Equal(t, []string{"c", "d", "e"}, m.Keys())
+
+		m = ringmap.NewLFURingMapOf[string, int](2)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Get("b")
+		m.Get("a")
+		m.Set("c", 3)
+		assert.Equal(t, []string{"b", "c"}, m.Keys())
+	})
+
+	t.Run("CountsUses", func(t *testing.T) {
+		p := ringmap.NewLFUPolicy[string]()
+		m := ringmap.NewRingMapWithPolicy[string, int](3, p)
+		m.Set("a", 1)
+		m.Get("a")
+		m.Set("a", 2)
+		m.Peek("a")
+		assert.Equal(t, uint64(3), p.Frequency("a"))
+		assert.Equal(t, uint64(0), p.Frequency("b"))
+
+		m.Put("a", 3)
+		assert.Equal(t, uint64(1), p.Frequency("a"))
+		m.Delete("a")
+		assert.Equal(t, uint64(0), p.Frequency("a"))
+		_, ok := p.Victim()
+		assert.False(t, ok)
+	})
+
+	t.Run("Decay", func(t *testing.T) {
+		p := ringmap.NewLFUPolicy[string]()
+		p.SetDecayPeriod(8)
+		m := ringmap.NewRingMapWithPolicy[string, int](3, p)
+		m.Set("a", 1)
+		for i := 0; i < 4; i++ {
+			m.Get("a")
+		}
+		m.Set("b", 2)
+		m.Get("b")
+		assert.Equal(t, uint64(5), p.Frequency("a"))
+		assert.Equal(t, uint64(2), p.Frequency("b"))
+
+		m.Set("c", 3) // the eighth use
+		assert.Equal(t, uint64(2), p.Frequency("a"))
+		assert.Equal(t, uint64(1), p.Frequency("b"))
+		assert.Equal(t, uint64(1), p.Frequency("c"))
+
+		// b and c tie after the decay, and b was inserted first.
+		victim, _ := p.Victim()
+		assert.Equal(t, "b", victim)
+	})
+
+	t.Run("NoDecay", func(t *testing.T) {
+		p := ringmap.NewLFUPolicy[string]()
+		p.SetDecayPeriod(-1)
+		m := ringmap.NewRingMapWithPolicy[string, int](1, p)
+		m.Set("a", 1)
+		for i := 0; i < 100; i++ {
+			m.Get("a")
+		}
+		assert.Equal(t, uint64(101), p.Frequency("a"))
+	})
+}
+
+func TestLFUPolicy_Trace(t *testing.T) {
+	t.Run("HotsetBeatsFIFO", func(t *testing.T) {
+		trace := hotsetTrace(rand.New(rand.NewSource(1)), 20000, 50, "")
+
+		fifo := replay(ringmap.NewRingMapOf[string, int](100), trace)
+		lfu := replay(ringmap.NewLFURingMapOf[string, int](100), trace)
+		assert.Greater(t, lfu, 0.45)
+		assert.Greater(t, lfu, fifo+0.1)
+	})
+
+	t.Run("DecayFollowsShift", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		before := hotsetTrace(r, 20000, 50, "old")
+		after := hotsetTrace(r, 10000, 50, "new")
+
+		aging := ringmap.NewLFURingMapOf[string, int](60)
+		replay(aging, before)
+
+		p := ringmap.NewLFUPolicy[string]()
+		p.SetDecayPeriod(-1)
+		frozen := ringmap.NewRingMapWithPolicy[string, int](60, p)
+		replay(frozen, before)
+
+		// Without the decay, the old hot keys have such high counts that the
+		// new ones never get to stay.
+		assert.Greater(t, replay(aging, after), replay(frozen, after)+0.1)
+	})
+}
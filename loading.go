@@ -0,0 +1,175 @@
+package ringmap
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Loader loads the value for a key that is missing from a LoadingRingMap.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// LoadingRingMap is a read-through cache in front of a SyncRingMap. A missing
+// key is loaded with its Loader and stored in the map, which evicts entries as
+// it always does. Concurrent misses for the same key share a single load.
+//
+// Errors returned by the loader are not cached unless SetErrorTTL is used.
+type LoadingRingMap[K comparable, V any] struct {
+	m    *SyncRingMap[K, V]
+	load Loader[K, V]
+
+	mu    sync.Mutex
+	calls map[K]*loadCall[V]
+	errs  *RingMapOf[K, error]
+}
+
+// loadCall is a load in progress. waiters and cancel are guarded by the mutex
+// of the map; value and err are set before done is closed.
+type loadCall[V any] struct {
+	done    chan struct{}
+	value   V
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// NewLoadingRingMap creates a read-through cache that stores the values loaded
+// by load in m. The caller may keep using m directly, for example to Set values
+// it already has.
+func NewLoadingRingMap[K comparable, V any](m *SyncRingMap[K, V], load Loader[K, V]) *LoadingRingMap[K, V] {
+	return &LoadingRingMap[K, V]{
+		m:     m,
+		load:  load,
+		calls: make(map[K]*loadCall[V]),
+		errs:  NewRingMapOf[K, error](m.Capacity()),
+	}
+}
+
+// Map returns the map the loaded values are stored in.
+func (l *LoadingRingMap[K, V]) Map() *SyncRingMap[K, V] {
+	return l.m
+}
+
+// SetErrorTTL sets how long an error returned by the loader is cached. While
+// it is, Get returns the error without calling the loader again. Zero, the
+// default, means errors aren't cached; negative TTLs are treated as zero.
+// Cached errors are removed when the TTL changes.
+func (l *LoadingRingMap[K, V]) SetErrorTTL(ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if ttl < 0 {
+		ttl = 0
+	}
+	for _, key := range l.errs.Keys() {
+		l.errs.Delete(key)
+	}
+	l.errs.SetDefaultTTL(ttl)
+}
+
+// SetClock sets the clock used for the time to live of values and of cached
+// errors. See RingMapOf.SetClock.
+func (l *LoadingRingMap[K, V]) SetClock(clock Clock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.m.SetClock(clock)
+	l.errs.SetClock(clock)
+}
+
+// Get returns the value for a key, loading it if it is missing. If a load for
+// the key is already in progress, Get waits for it instead of starting another.
+//
+// If ctx is done before the value is loaded, Get returns ctx.Err(). The load
+// itself goes on as long as any caller still waits for it; once the last one
+// gives up, the context passed to the loader is cancelled and whatever the
+// loader returns is discarded. That context carries the values of the ctx that
+// started the load, but not its deadline.
+func (l *LoadingRingMap[K, V]) Get(ctx context.Context, key K) (V, error) {
+	if value, ok := l.m.Get(key); ok {
+		return value, nil
+	}
+
+	l.mu.Lock()
+	// A load may have stored the value since the miss.
+	if value, ok := l.m.Peek(key); ok {
+		l.mu.Unlock()
+		return value, nil
+	}
+	if err, ok := l.errs.Get(key); ok {
+		l.mu.Unlock()
+		var zero V
+		return zero, err
+	}
+
+	c, ok := l.calls[key]
+	if !ok {
+		loadCtx, cancel := context.WithCancel(detachedContext{ctx})
+		c = &loadCall[V]{done: make(chan struct{}), cancel: cancel}
+		l.calls[key] = c
+		go l.run(loadCtx, key, c)
+	}
+	c.waiters++
+	l.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		l.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 && l.calls[key] == c {
+			// Nobody waits for the load anymore, so the next Get starts over.
+			delete(l.calls, key)
+			c.cancel()
+		}
+		l.mu.Unlock()
+
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// run calls the loader and stores its result.
+func (l *LoadingRingMap[K, V]) run(ctx context.Context, key K, c *loadCall[V]) {
+	defer c.cancel()
+	c.value, c.err = l.load(ctx, key)
+
+	l.mu.Lock()
+	if l.calls[key] == c {
+		delete(l.calls, key)
+		if c.err == nil {
+			l.m.Set(key, c.value)
+		} else if l.errs.DefaultTTL() > 0 {
+			l.errs.Set(key, c.err)
+		}
+	}
+	l.mu.Unlock()
+
+	close(c.done)
+}
+
+// Delete removes the value and any cached error for a key. It returns true if
+// there was a value. A load in progress is not affected.
+func (l *LoadingRingMap[K, V]) Delete(key K) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.errs.Delete(key)
+
+	return l.m.Delete(key)
+}
+
+// detachedContext is a context with the values of its parent, but without its
+// deadline and cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
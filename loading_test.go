@@ -0,0 +1,193 @@
+package ringmap_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+var errLoad = errors.New("load failed")
+
+// countingLoader returns a loader that counts its calls and returns the key as
+// a string, or errLoad for negative keys.
+func countingLoader(calls *int32) ringmap.Loader[int, string] {
+	return func(ctx context.Context, key int) (string, error) {
+		atomic.AddInt32(calls, 1)
+		if key < 0 {
+			return "", errLoad
+		}
+		return strconv.Itoa(key), nil
+	}
+}
+
+func TestLoadingRingMap(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("LoadsOnMiss", func(t *testing.T) {
+		var calls int32
+		l := ringmap.NewLoadingRingMap(ringmap.NewSyncRingMap[int, string](ringMapCapacity), countingLoader(&calls))
+
+		value, err := l.Get(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", value)
+		value, err = l.Get(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", value)
+		assert.Equal(t, int32(1), calls)
+		assert.Equal(t, []int{1}, l.Map().Keys())
+	})
+
+	t.Run("EvictsLikeTheMap", func(t *testing.T) {
+		var calls int32
+		l := ringmap.NewLoadingRingMap(ringmap.NewSyncRingMap[int, string](2), countingLoader(&calls))
+		for _, key := range []int{1, 2, 3, 1} {
+			_, err := l.Get(ctx, key)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, []int{3, 1}, l.Map().Keys())
+		assert.Equal(t, int32(4), calls)
+	})
+
+	t.Run("DeduplicatesConcurrentMisses", func(t *testing.T) {
+		var calls int32
+		release := make(chan struct{})
+		l := ringmap.NewLoadingRingMap(ringmap.NewSyncRingMap[int, string](ringMapCapacity),
+			func(ctx context.Context, key int) (string, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return "loaded", nil
+			})
+
+		var wg sync.WaitGroup
+		values := make([]string, 20)
+		for i := range values {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				values[i], _ = l.Get(ctx, 1)
+			}(i)
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls)
+		for _, value := range values {
+			assert.Equal(t, "loaded", value)
+		}
+	})
+
+	t.Run("ErrorsArentCachedByDefault", func(t *testing.T) {
+		var calls int32
+		l := ringmap.NewLoadingRingMap(ringmap.NewSyncRingMap[int, string](ringMapCapacity), countingLoader(&calls))
+
+		_, err := l.Get(ctx, -1)
+		assert.ErrorIs(t, err, errLoad)
+		_, err = l.Get(ctx, -1)
+		assert.ErrorIs(t, err, errLoad)
+		assert.Equal(t, int32(2), calls)
+		assert.Equal(t, 0, l.Map().Len())
+	})
+
+	t.Run("CachesErrors", func(t *testing.T) {
+		var calls int32
+		clock := newFakeClock()
+		l := ringmap.NewLoadingRingMap(ringmap.NewSyncRingMap[int, string](ringMapCapacity), countingLoader(&calls))
+		l.SetClock(clock)
+		l.SetErrorTTL(time.Minute)
+
+		_, err := l.Get(ctx, -1)
+		assert.ErrorIs(t, err, errLoad)
+		clock.Advance(59 * time.Second)
+		_, err = l.Get(ctx, -1)
+		assert.ErrorIs(t, err, errLoad)
+		assert.Equal(t, int32(1), calls)
+
+		clock.Advance(time.Second)
+		_, err = l.Get(ctx, -1)
+		assert.ErrorIs(t, err, errLoad)
+		assert.Equal(t, int32(2), calls)
+
+		assert.False(t, l.Delete(-1))
+		_, err = l.Get(ctx, -1)
+		assert.ErrorIs(t, err, errLoad)
+		assert.Equal(t, int32(3), calls)
+	})
+
+	t.Run("ContextCancellation", func(t *testing.T) {
+		started := make(chan struct{})
+		cancelled := make(chan struct{})
+		l := ringmap.NewLoadingRingMap(ringmap.NewSyncRingMap[int, string](ringMapCapacity),
+			func(ctx context.Context, key int) (string, error) {
+				close(started)
+				<-ctx.Done()
+				close(cancelled)
+				return "", ctx.Err()
+			})
+
+		getCtx, cancel := context.WithCancel(ctx)
+		errs := make(chan error)
+		go func() {
+			_, err := l.Get(getCtx, 1)
+			errs <- err
+		}()
+		<-started
+		cancel()
+
+		assert.ErrorIs(t, <-errs, context.Canceled)
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("the load wasn't cancelled")
+		}
+		assert.Equal(t, 0, l.Map().Len())
+	})
+
+	t.Run("LoadOutlivesCancelledWaiter", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var loadErr error
+		l := ringmap.NewLoadingRingMap(ringmap.NewSyncRingMap[int, string](ringMapCapacity),
+			func(ctx context.Context, key int) (string, error) {
+				close(started)
+				<-release
+				loadErr = ctx.Err()
+				return "loaded", nil
+			})
+
+		patient := make(chan string)
+		go func() {
+			value, _ := l.Get(ctx, 1)
+			patient <- value
+		}()
+		<-started
+
+		impatientCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err := l.Get(impatientCtx, 1)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(release)
+		assert.Equal(t, "loaded", <-patient)
+		assert.NoError(t, loadErr)
+	})
+
+	t.Run("LoaderSeesContextValues", func(t *testing.T) {
+		type ctxKey struct{}
+		l := ringmap.NewLoadingRingMap(ringmap.NewSyncRingMap[int, string](ringMapCapacity),
+			func(ctx context.Context, key int) (string, error) {
+				return ctx.Value(ctxKey{}).(string), nil
+			})
+
+		value, err := l.Get(context.WithValue(ctx, ctxKey{}, "from ctx"), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "from ctx", value)
+	})
+}
@@ -0,0 +1,153 @@
+package ringmap
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StatsSource is a map whose statistics can be exported. RingMap, SyncRingMap
+// and ShardedRingMap all implement it. A RingMap isn't safe for concurrent use,
+// so only register one if nothing else uses it while metrics are scraped.
+type StatsSource interface {
+	Stats() Stats
+	Len() int
+	Capacity() int
+}
+
+// Exporter renders the statistics of maps in the Prometheus text exposition
+// format. Each map is registered under a name, which becomes the value of its
+// "map" label. An Exporter is an http.Handler, so it can be served as is:
+//
+//	exporter := ringmap.NewExporter()
+//	exporter.Register("sessions", sessions)
+//	http.Handle("/metrics", exporter)
+type Exporter struct {
+	mu   sync.Mutex
+	maps map[string]StatsSource
+}
+
+// NewExporter creates a new Exporter without any maps.
+func NewExporter() *Exporter {
+	return &Exporter{maps: make(map[string]StatsSource)}
+}
+
+// Register adds a map under a name. It returns ErrDuplicateName if another map
+// is registered under the same name.
+func (e *Exporter) Register(name string, m StatsSource) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.maps[name]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateName, name)
+	}
+	e.maps[name] = m
+
+	return nil
+}
+
+// Unregister removes the map registered under a name. It returns false if there
+// is none.
+func (e *Exporter) Unregister(name string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	_, ok := e.maps[name]
+	delete(e.maps, name)
+
+	return ok
+}
+
+// metricsSnapshot holds the metrics of one map.
+type metricsSnapshot struct {
+	name     string
+	length   int
+	capacity int
+	stats    Stats
+}
+
+// snapshot returns the metrics of all maps, ordered by name.
+func (e *Exporter) snapshot() []metricsSnapshot {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	snapshots := make([]metricsSnapshot, 0, len(e.maps))
+	for name, m := range e.maps {
+		snapshots = append(snapshots, metricsSnapshot{
+			name:     name,
+			length:   m.Len(),
+			capacity: m.Capacity(),
+			stats:    m.Stats(),
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].name < snapshots[j].name
+	})
+
+	return snapshots
+}
+
+// WriteTo writes the metrics of all maps to w in the Prometheus text
+// exposition format.
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	snapshots := e.snapshot()
+
+	var buf bytes.Buffer
+	family := func(name, kind, help string, value func(s metricsSnapshot) string) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, s := range snapshots {
+			fmt.Fprintf(&buf, "%s{map=\"%s\"} %s\n", name, escapeLabel(s.name), value(s))
+		}
+	}
+
+	family("ringmap_length", "gauge", "Number of entries in the map.", func(s metricsSnapshot) string {
+		return strconv.Itoa(s.length)
+	})
+	family("ringmap_capacity", "gauge", "Maximum number of entries in the map.", func(s metricsSnapshot) string {
+		if s.capacity < 0 {
+			return "+Inf"
+		}
+		return strconv.Itoa(s.capacity)
+	})
+	family("ringmap_hits_total", "counter", "Lookups that found their key.", func(s metricsSnapshot) string {
+		return strconv.FormatUint(s.stats.Hits, 10)
+	})
+	family("ringmap_misses_total", "counter", "Lookups that didn't find their key.", func(s metricsSnapshot) string {
+		return strconv.FormatUint(s.stats.Misses, 10)
+	})
+
+	const evictions = "ringmap_evictions_total"
+	fmt.Fprintf(&buf, "# HELP %s Entries that left the map, by reason.\n# TYPE %s counter\n", evictions, evictions)
+	for _, s := range snapshots {
+		reasons := make([]EvictReason, 0, len(s.stats.Evictions))
+		for reason := range s.stats.Evictions {
+			reasons = append(reasons, reason)
+		}
+		sort.Slice(reasons, func(i, j int) bool { return reasons[i] < reasons[j] })
+
+		for _, reason := range reasons {
+			fmt.Fprintf(&buf, "%s{map=\"%s\",reason=\"%s\"} %d\n",
+				evictions, escapeLabel(s.name), reason, s.stats.Evictions[reason])
+		}
+	}
+
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP writes the metrics of all maps as the response.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.WriteTo(w)
+}
+
+// labelEscaper escapes label values as the exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,97 @@
+package ringmap_test
+
+import (
+	"bytes"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// assertGolden compares got to testdata/name, or rewrites the file with -update.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		assert.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+
+	want, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestExporter(t *testing.T) {
+	t.Run("Golden", func(t *testing.T) {
+		sessions := ringmap.NewSyncRingMap[string, int](3)
+		for _, key := range []string{"a", "b", "c", "d"} {
+			sessions.Set(key, 1)
+		}
+		sessions.Get("d")
+		sessions.Get("a")
+		sessions.Delete("d")
+
+		users := ringmap.NewShardedRingMap[int, string](ringmap.Unbounded, 2)
+		users.Set(1, "x")
+		users.Set(1, "y")
+		users.Get(1)
+
+		exporter := ringmap.NewExporter()
+		assert.NoError(t, exporter.Register("sessions", sessions))
+		assert.NoError(t, exporter.Register("users", users))
+
+		var buf bytes.Buffer
+		n, err := exporter.WriteTo(&buf)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(buf.Len()), n)
+		assertGolden(t, "metrics.golden", buf.Bytes())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := ringmap.NewExporter().WriteTo(&buf)
+		assert.NoError(t, err)
+		assertGolden(t, "metrics_empty.golden", buf.Bytes())
+	})
+
+	t.Run("EscapesNames", func(t *testing.T) {
+		exporter := ringmap.NewExporter()
+		assert.NoError(t, exporter.Register("a \"quoted\"\\name\n", ringmap.NewRingMapOf[int, int](1)))
+
+		var buf bytes.Buffer
+		_, err := exporter.WriteTo(&buf)
+		assert.NoError(t, err)
+		assertGolden(t, "metrics_escaped.golden", buf.Bytes())
+	})
+
+	t.Run("DuplicateName", func(t *testing.T) {
+		exporter := ringmap.NewExporter()
+		assert.NoError(t, exporter.Register("a", ringmap.NewRingMapOf[int, int](1)))
+		assert.ErrorIs(t, exporter.Register("a", ringmap.NewRingMapOf[int, int](1)), ringmap.ErrDuplicateName)
+
+		assert.True(t, exporter.Unregister("a"))
+		assert.False(t, exporter.Unregister("a"))
+		assert.NoError(t, exporter.Register("a", ringmap.NewRingMapOf[int, int](1)))
+	})
+
+	t.Run("ServeHTTP", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[string, int](3)
+		m.Set("a", 1)
+		exporter := ringmap.NewExporter()
+		assert.NoError(t, exporter.Register("m", m))
+
+		rec := httptest.NewRecorder()
+		exporter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Body.String(), "ringmap_length{map=\"m\"} 1\n")
+	})
+}
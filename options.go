@@ -0,0 +1,202 @@
+package ringmap
+
+import (
+	"fmt"
+	"time"
+)
+
+// Unbounded is the capacity of a map that never evicts to make room.
+const Unbounded = -1
+
+// options collects the settings for New. Settings that depend on the key and
+// value types are kept untyped until New checks them.
+type options struct {
+	capacity    int
+	hasCapacity bool
+	policy      interface{}
+	ttl         time.Duration
+	onEvict     interface{}
+	clock       Clock
+	weigher     interface{}
+	maxWeight   int64
+	keyCodec    interface{}
+	valueCodec  interface{}
+}
+
+// Option configures a map created by New.
+type Option func(*options) error
+
+// WithCapacity sets the maximum number of entries. A capacity of zero rejects
+// every insert and Unbounded never evicts to make room; any other negative
+// capacity is invalid. Every map needs a capacity.
+func WithCapacity(capacity int) Option {
+	return func(o *options) error {
+		if capacity < 0 && capacity != Unbounded {
+			return fmt.Errorf("%w: %d", ErrInvalidCapacity, capacity)
+		}
+		o.capacity = capacity
+		o.hasCapacity = true
+
+		return nil
+	}
+}
+
+// WithPolicy sets the eviction policy. The default is a FIFOPolicy.
+func WithPolicy[K comparable](policy EvictionPolicy[K]) Option {
+	return func(o *options) error {
+		if policy == nil {
+			return fmt.Errorf("%w: nil policy", ErrInvalidOption)
+		}
+		o.policy = policy
+
+		return nil
+	}
+}
+
+// WithTTL sets the default time to live for entries. Zero, the default, means
+// entries don't expire; a negative TTL is invalid.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) error {
+		if ttl < 0 {
+			return fmt.Errorf("%w: %v", ErrInvalidTTL, ttl)
+		}
+		o.ttl = ttl
+
+		return nil
+	}
+}
+
+// WithOnEvict sets the function that is called for every entry that leaves the
+// map. See RingMapOf.OnEvict.
+func WithOnEvict[K comparable, V any](fn func(key K, value V, reason EvictReason)) Option {
+	return func(o *options) error {
+		if fn == nil {
+			return fmt.Errorf("%w: nil eviction callback", ErrInvalidOption)
+		}
+		o.onEvict = EvictFunc[K, V](fn)
+
+		return nil
+	}
+}
+
+// WithClock sets the clock used to expire entries. The default is the system
+// clock.
+func WithClock(clock Clock) Option {
+	return func(o *options) error {
+		if clock == nil {
+			return fmt.Errorf("%w: nil clock", ErrInvalidOption)
+		}
+		o.clock = clock
+
+		return nil
+	}
+}
+
+// WithWeigher sets the function that weighs entries towards the maximum weight.
+// Without one, values that implement Sizer weigh their Size() and all other
+// values weigh 1.
+func WithWeigher[K comparable, V any](fn func(key K, value V) int64) Option {
+	return func(o *options) error {
+		if fn == nil {
+			return fmt.Errorf("%w: nil weigher", ErrInvalidOption)
+		}
+		o.weigher = Weigher[K, V](fn)
+
+		return nil
+	}
+}
+
+// WithMaxWeight bounds the total weight of the entries in the map, on top of
+// its capacity. Unbounded, the default, means there is no such bound; any
+// other negative weight is invalid.
+func WithMaxWeight(maxWeight int64) Option {
+	return func(o *options) error {
+		if maxWeight < 0 && maxWeight != Unbounded {
+			return fmt.Errorf("%w: %d", ErrInvalidWeight, maxWeight)
+		}
+		o.maxWeight = maxWeight
+
+		return nil
+	}
+}
+
+// WithCodec sets the codecs used to encode keys and values in snapshots. A nil
+// codec keeps the default GobCodec.
+func WithCodec[K comparable, V any](keys Codec[K], values Codec[V]) Option {
+	return func(o *options) error {
+		if keys != nil {
+			o.keyCodec = keys
+		}
+		if values != nil {
+			o.valueCodec = values
+		}
+
+		return nil
+	}
+}
+
+// New creates a new ordered map that holds keys of type K and values of type V,
+// configured by opts. WithCapacity is required. New returns an error wrapping
+// ErrInvalidCapacity, ErrInvalidTTL, ErrInvalidWeight or ErrInvalidOption if
+// an option is invalid, including options for other key or value types.
+func New[K comparable, V any](opts ...Option) (*RingMapOf[K, V], error) {
+	o := options{maxWeight: Unbounded}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	if !o.hasCapacity {
+		return nil, fmt.Errorf("%w: no capacity given", ErrInvalidCapacity)
+	}
+
+	policy := EvictionPolicy[K](NewFIFOPolicy[K]())
+	if o.policy != nil {
+		p, ok := o.policy.(EvictionPolicy[K])
+		if !ok {
+			return nil, fmt.Errorf("%w: policy %T is not an EvictionPolicy[%s]", ErrInvalidOption, o.policy, typeName[K]())
+		}
+		policy = p
+	}
+
+	m := NewRingMapWithPolicy[K, V](o.capacity, policy)
+	m.SetDefaultTTL(o.ttl)
+	m.SetClock(o.clock)
+	if o.onEvict != nil {
+		fn, ok := o.onEvict.(EvictFunc[K, V])
+		if !ok {
+			return nil, fmt.Errorf("%w: eviction callback %T is not an EvictFunc[%s, %s]", ErrInvalidOption, o.onEvict, typeName[K](), typeName[V]())
+		}
+		m.OnEvict(fn)
+	}
+	if o.weigher != nil {
+		fn, ok := o.weigher.(Weigher[K, V])
+		if !ok {
+			return nil, fmt.Errorf("%w: weigher %T is not a Weigher[%s, %s]", ErrInvalidOption, o.weigher, typeName[K](), typeName[V]())
+		}
+		m.weigher = fn
+	}
+	m.maxWeight = o.maxWeight
+	if o.keyCodec != nil {
+		codec, ok := o.keyCodec.(Codec[K])
+		if !ok {
+			return nil, fmt.Errorf("%w: key codec %T is not a Codec[%s]", ErrInvalidOption, o.keyCodec, typeName[K]())
+		}
+		m.keyCodec = codec
+	}
+	if o.valueCodec != nil {
+		codec, ok := o.valueCodec.(Codec[V])
+		if !ok {
+			return nil, fmt.Errorf("%w: value codec %T is not a Codec[%s]", ErrInvalidOption, o.valueCodec, typeName[V]())
+		}
+		m.valueCodec = codec
+	}
+
+	return m, nil
+}
+
+// typeName returns the name of type T for error messages.
+func typeName[T any]() string {
+	return fmt.Sprintf("%T", (*T)(nil))[1:]
+}
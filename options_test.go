@@ -0,0 +1,139 @@
+package ringmap_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("WithCapacity", func(t *testing.T) {
+		m, err := ringmap.New[string, int](ringmap.WithCapacity(10))
+		assert.NoError(t, err)
+		assert.Equal(t, 10, m.Capacity())
+		assert.IsType(t, &ringmap.FIFOPolicy[string]{}, m.Policy())
+	})
+
+	t.Run("CapacityIsRequired", func(t *testing.T) {
+		m, err := ringmap.New[string, int]()
+		assert.Nil(t, m)
+		assert.ErrorIs(t, err, ringmap.ErrInvalidCapacity)
+	})
+
+	t.Run("NegativeCapacityIsInvalid", func(t *testing.T) {
+		_, err := ringmap.New[string, int](ringmap.WithCapacity(-2))
+		assert.ErrorIs(t, err, ringmap.ErrInvalidCapacity)
+	})
+
+	t.Run("Unbounded", func(t *testing.T) {
+		m, err := ringmap.New[int, bool](ringmap.WithCapacity(ringmap.Unbounded))
+		assert.NoError(t, err)
+		for i := 0; i < 1000; i++ {
+			m.Set(i, true)
+		}
+		assert.Equal(t, 1000, m.Len())
+		assert.Equal(t, ringmap.Unbounded, m.Capacity())
+		assert.False(t, m.IsFull())
+	})
+
+	t.Run("ZeroCapacityRejectsEveryInsert", func(t *testing.T) {
+		m, err := ringmap.New[string, int](ringmap.WithCapacity(0))
+		assert.NoError(t, err)
+		assert.True(t, m.IsFull())
+
+		assert.False(t, m.Set("a", 1))
+		assert.False(t, m.Put("a", 1))
+		assert.False(t, m.SetWithTTL("a", 1, time.Minute))
+
+		added, err := m.TrySet("a", 1)
+		assert.False(t, added)
+		assert.ErrorIs(t, err, ringmap.ErrZeroCapacity)
+		_, err = m.TryPut("a", 1)
+		assert.ErrorIs(t, err, ringmap.ErrZeroCapacity)
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("NewRingMapWithZeroCapacityDoesntPanic", func(t *testing.T) {
+		m := ringmap.NewRingMap(0)
+		assert.NotPanics(t, func() { m.Set("a", 1) })
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("WithPolicy", func(t *testing.T) {
+		m, err := ringmap.New[string, int](
+			ringmap.WithCapacity(2),
+			ringmap.WithPolicy[string](ringmap.NewLRUPolicy[string]()),
+		)
+		assert.NoError(t, err)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Get("a")
+		m.Set("c", 3)
+		assert.Equal(t, []string{"a", "c"}, m.Keys())
+	})
+
+	t.Run("PolicyForOtherKeyType", func(t *testing.T) {
+		_, err := ringmap.New[string, int](
+			ringmap.WithCapacity(2),
+			ringmap.WithPolicy[int](ringmap.NewLRUPolicy[int]()),
+		)
+		assert.ErrorIs(t, err, ringmap.ErrInvalidOption)
+	})
+
+	t.Run("NilPolicy", func(t *testing.T) {
+		_, err := ringmap.New[string, int](
+			ringmap.WithCapacity(2),
+			ringmap.WithPolicy[string](nil),
+		)
+		assert.ErrorIs(t, err, ringmap.ErrInvalidOption)
+	})
+
+	t.Run("WithTTLAndClock", func(t *testing.T) {
+		clock := newFakeClock()
+		m, err := ringmap.New[string, int](
+			ringmap.WithCapacity(10),
+			ringmap.WithTTL(time.Minute),
+			ringmap.WithClock(clock),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Minute, m.DefaultTTL())
+		m.Set("a", 1)
+		clock.Advance(time.Minute)
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("InvalidTTL", func(t *testing.T) {
+		_, err := ringmap.New[string, int](ringmap.WithCapacity(10), ringmap.WithTTL(-time.Second))
+		assert.ErrorIs(t, err, ringmap.ErrInvalidTTL)
+	})
+
+	t.Run("NilClock", func(t *testing.T) {
+		_, err := ringmap.New[string, int](ringmap.WithCapacity(10), ringmap.WithClock(nil))
+		assert.ErrorIs(t, err, ringmap.ErrInvalidOption)
+	})
+
+	t.Run("WithOnEvict", func(t *testing.T) {
+		var evicted []string
+		m, err := ringmap.New[string, int](
+			ringmap.WithCapacity(1),
+			ringmap.WithOnEvict(func(key string, _ int, reason ringmap.EvictReason) {
+				evicted = append(evicted, key+" "+reason.String())
+			}),
+		)
+		assert.NoError(t, err)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		assert.Equal(t, []string{"a capacity"}, evicted)
+	})
+
+	t.Run("OnEvictForOtherTypes", func(t *testing.T) {
+		_, err := ringmap.New[string, int](
+			ringmap.WithCapacity(1),
+			ringmap.WithOnEvict(func(string, string, ringmap.EvictReason) {}),
+		)
+		assert.ErrorIs(t, err, ringmap.ErrInvalidOption)
+		assert.Contains(t, err.Error(), "EvictFunc[string, int]")
+	})
+}
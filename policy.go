@@ -0,0 +1,233 @@
+package ringmap
+
+// EvictionPolicy decides which element leaves a full RingMap to make room for a
+// new key. The map tells its policy about every insert, access, update and
+// delete of a key, and asks it for a victim when it's full.
+//
+// The map keeps its elements in a single list, which is what Front(), Back()
+// and Keys() report. A policy may reorder that list through the Ordering it's
+// attached to, for example to keep the next victim at the front. Policies
+// that track their keys in lists of their own, like LFUPolicy, ARCPolicy and
+// TinyLFUPolicy, don't reorder the map, so Front() is the oldest element
+// rather than the next victim.
+//
+// All methods are called by the map while it is being modified, so a policy
+// must not call back into the map itself.
+type EvictionPolicy[K comparable] interface {
+	// Attach is called once, when the policy is given to a map.
+	Attach(o Ordering[K])
+
+	// OnInsert is called after a new key was added at the back of the map.
+	OnInsert(key K)
+
+	// OnAccess is called when the value of a key was read with Get or
+	// GetOrDefault.
+	OnAccess(key K)
+
+	// OnUpdate is called when the value of an existing key was replaced with
+	// Set.
+	OnUpdate(key K)
+
+	// OnDelete is called after a key was removed from the map, whether it was
+	// deleted, evicted or moved by Put.
+	OnDelete(key K)
+
+	// Victim returns the key to evict from the full map. The second return
+	// parameter is false if there is nothing to evict.
+	Victim() (key K, ok bool)
+}
+
+// ConcurrentAccessPolicy is implemented by eviction policies whose OnAccess
+// may be called by many goroutines at once. SyncRingMap only takes its read
+// lock for Get and GetOrDefault if the policy reports true; otherwise an
+// access takes the write lock.
+type ConcurrentAccessPolicy interface {
+	ConcurrentAccess() bool
+}
+
+// CandidatePolicy is implemented by eviction policies that need to know which
+// new key their victims make room for. The map calls OnCandidate before it
+// evicts anything for a new key, and before the key's OnInsert. The insert may
+// still fail afterwards, for example with ErrFull.
+type CandidatePolicy[K comparable] interface {
+	OnCandidate(key K)
+}
+
+// Ordering is the view of a RingMap's list of keys that its EvictionPolicy is
+// attached to. Methods that take a key do nothing, or return false, for a key
+// that isn't in the map.
+type Ordering[K comparable] interface {
+	// Front returns the first key in the map.
+	Front() (K, bool)
+
+	// Back returns the last key in the map.
+	Back() (K, bool)
+
+	// Next returns the key that follows key.
+	Next(key K) (K, bool)
+
+	// Prev returns the key that precedes key.
+	Prev(key K) (K, bool)
+
+	// MoveToFront moves key to the front of the map.
+	MoveToFront(key K)
+
+	// MoveToBack moves key to the back of the map.
+	MoveToBack(key K)
+
+	// MoveBefore moves key in front of mark. It does nothing if mark isn't in
+	// the map either.
+	MoveBefore(key, mark K)
+
+	// Len returns the number of elements in the map.
+	Len() int
+
+	// Capacity returns the capacity of the map.
+	Capacity() int
+}
+
+// ordering implements Ordering on top of a RingMap.
+type ordering[K comparable, V any] struct {
+	m *RingMapOf[K, V]
+}
+
+func (o ordering[K, V]) Front() (K, bool) {
+	return o.key(o.m.slots.front)
+}
+
+func (o ordering[K, V]) Back() (K, bool) {
+	return o.key(o.m.slots.back)
+}
+
+func (o ordering[K, V]) Next(key K) (K, bool) {
+	if i, ok := o.m.items[key]; ok {
+		return o.key(o.m.slots.at(i).next)
+	}
+
+	var zero K
+	return zero, false
+}
+
+func (o ordering[K, V]) Prev(key K) (K, bool) {
+	if i, ok := o.m.items[key]; ok {
+		return o.key(o.m.slots.at(i).prev)
+	}
+
+	var zero K
+	return zero, false
+}
+
+func (o ordering[K, V]) MoveToFront(key K) {
+	if i, ok := o.m.items[key]; ok {
+		o.m.slots.moveToFront(i)
+	}
+}
+
+func (o ordering[K, V]) MoveToBack(key K) {
+	if i, ok := o.m.items[key]; ok {
+		o.m.slots.moveToBack(i)
+	}
+}
+
+func (o ordering[K, V]) MoveBefore(key, mark K) {
+	i, ok := o.m.items[key]
+	j, markOK := o.m.items[mark]
+	if ok && markOK {
+		o.m.slots.moveBefore(i, j)
+	}
+}
+
+func (o ordering[K, V]) Len() int {
+	return o.m.Len()
+}
+
+func (o ordering[K, V]) Capacity() int {
+	return o.m.Capacity()
+}
+
+// key returns the key in the slot at index i, or false for none.
+func (o ordering[K, V]) key(i int) (K, bool) {
+	if i == none {
+		var zero K
+		return zero, false
+	}
+
+	return o.m.slots.at(i).key, true
+}
+
+// FIFOPolicy evicts the element at the front of the map, which is the oldest
+// one since it never reorders the map. It is the default policy.
+type FIFOPolicy[K comparable] struct {
+	o Ordering[K]
+}
+
+// NewFIFOPolicy creates a new FIFO eviction policy.
+func NewFIFOPolicy[K comparable]() *FIFOPolicy[K] {
+	return &FIFOPolicy[K]{}
+}
+
+// Attach implements EvictionPolicy.
+func (p *FIFOPolicy[K]) Attach(o Ordering[K]) {
+	p.o = o
+}
+
+// OnInsert implements EvictionPolicy.
+func (p *FIFOPolicy[K]) OnInsert(key K) {}
+
+// OnAccess implements EvictionPolicy.
+func (p *FIFOPolicy[K]) OnAccess(key K) {}
+
+// OnUpdate implements EvictionPolicy.
+func (p *FIFOPolicy[K]) OnUpdate(key K) {}
+
+// OnDelete implements EvictionPolicy.
+func (p *FIFOPolicy[K]) OnDelete(key K) {}
+
+// Victim implements EvictionPolicy. It returns the front key.
+func (p *FIFOPolicy[K]) Victim() (K, bool) {
+	return p.o.Front()
+}
+
+// ConcurrentAccess implements ConcurrentAccessPolicy. Accesses don't touch the
+// policy at all.
+func (p *FIFOPolicy[K]) ConcurrentAccess() bool {
+	return true
+}
+
+// LRUPolicy moves an element to the back of the map whenever it's read or
+// replaced, and evicts the element at the front, which is the least recently
+// used one.
+type LRUPolicy[K comparable] struct {
+	o Ordering[K]
+}
+
+// NewLRUPolicy creates a new LRU eviction policy.
+func NewLRUPolicy[K comparable]() *LRUPolicy[K] {
+	return &LRUPolicy[K]{}
+}
+
+// Attach implements EvictionPolicy.
+func (p *LRUPolicy[K]) Attach(o Ordering[K]) {
+	p.o = o
+}
+
+// OnInsert implements EvictionPolicy.
+func (p *LRUPolicy[K]) OnInsert(key K) {}
+
+// OnAccess implements EvictionPolicy. It moves key to the back.
+func (p *LRUPolicy[K]) OnAccess(key K) {
+	p.o.MoveToBack(key)
+}
+
+// OnUpdate implements EvictionPolicy. It moves key to the back.
+func (p *LRUPolicy[K]) OnUpdate(key K) {
+	p.o.MoveToBack(key)
+}
+
+// OnDelete implements EvictionPolicy.
+func (p *LRUPolicy[K]) OnDelete(key K) {}
+
+// Victim implements EvictionPolicy. It returns the front key.
+func (p *LRUPolicy[K]) Victim() (K, bool) {
+	return p.o.Front()
+}
@@ -0,0 +1,229 @@
+package ringmap_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingPolicy is a FIFO policy that records every call it gets.
+type recordingPolicy struct {
+	ringmap.FIFOPolicy[string]
+	calls []string
+}
+
+func (p *recordingPolicy) OnInsert(key string) { p.calls = append(p.calls, "insert "+key) }
+func (p *recordingPolicy) OnAccess(key string) { p.calls = append(p.calls, "access "+key) }
+func (p *recordingPolicy) OnUpdate(key string) { p.calls = append(p.calls, "update "+key) }
+func (p *recordingPolicy) OnDelete(key string) { p.calls = append(p.calls, "delete "+key) }
+
+// candidatePolicy also records the candidates it's told about.
+type candidatePolicy struct {
+	recordingPolicy
+}
+
+func (p *candidatePolicy) OnCandidate(key string) { p.calls = append(p.calls, "candidate "+key) }
+
+// newestPolicy evicts the most recently inserted key.
+type newestPolicy struct {
+	o ringmap.Ordering[int]
+}
+
+func (p *newestPolicy) Attach(o ringmap.Ordering[int]) { p.o = o }
+func (p *newestPolicy) OnInsert(int)                   {}
+func (p *newestPolicy) OnAccess(int)                   {}
+func (p *newestPolicy) OnUpdate(int)                   {}
+func (p *newestPolicy) OnDelete(int)                   {}
+func (p *newestPolicy) Victim() (int, bool)            { return p.o.Back() }
+
+// pinnedPolicy never evicts anything.
+type pinnedPolicy struct {
+	newestPolicy
+}
+
+func (p *pinnedPolicy) Victim() (int, bool) { return 0, false }
+
+func TestEvictionPolicy(t *testing.T) {
+	t.Run("MapCallsHooks", func(t *testing.T) {
+		p := &recordingPolicy{}
+		m := ringmap.NewRingMapWithPolicy[string, int](2, p)
+		m.Set("a", 1)
+		m.Set("a", 2)
+		m.Get("a")
+		m.GetOrDefault("b", 0)
+		m.Peek("a")
+		m.Set("b", 1)
+		m.Set("c", 1)
+		m.Put("b", 2)
+		m.Delete("c")
+
+		assert.Equal(t, []string{
+			"insert a",
+			"update a",
+			"access a",
+			"insert b",
+			"delete a", // evicted from the front
+			"insert c",
+			"delete b", // Put moves b to the back
+			"insert b",
+			"delete c",
+		}, p.calls)
+	})
+
+	t.Run("CustomVictim", func(t *testing.T) {
+		m := ringmap.NewRingMapWithPolicy[int, bool](3, &newestPolicy{})
+		for i := 1; i <= 5; i++ {
+			m.Set(i, true)
+		}
+		assert.Equal(t, []int{1, 2, 5}, m.Keys())
+	})
+
+	t.Run("NoVictim", func(t *testing.T) {
+		m := ringmap.NewRingMapWithPolicy[int, bool](1, &pinnedPolicy{})
+		assert.True(t, m.Set(1, true))
+		assert.False(t, m.Set(2, true))
+		_, err := m.TryPut(2, true)
+		assert.ErrorIs(t, err, ringmap.ErrFull)
+		assert.Equal(t, []int{1}, m.Keys())
+	})
+
+	t.Run("Candidate", func(t *testing.T) {
+		p := &candidatePolicy{}
+		m := ringmap.NewRingMapWithPolicy[string, int](1, p)
+		m.Set("a", 1)
+		m.Set("a", 2)
+		m.Set("b", 1)
+
+		assert.Equal(t, []string{
+			"candidate a",
+			"insert a",
+			"update a",
+			"candidate b",
+			"delete a",
+			"insert b",
+		}, p.calls)
+	})
+
+	t.Run("ConcurrentAccess", func(t *testing.T) {
+		var p interface{} = ringmap.NewFIFOPolicy[int]()
+		c, ok := p.(ringmap.ConcurrentAccessPolicy)
+		assert.True(t, ok)
+		assert.True(t, c.ConcurrentAccess())
+
+		p = ringmap.NewLRUPolicy[int]()
+		_, ok = p.(ringmap.ConcurrentAccessPolicy)
+		assert.False(t, ok)
+	})
+}
+
+func TestOrdering(t *testing.T) {
+	p := &newestPolicy{}
+	m := ringmap.NewRingMapWithPolicy[int, bool](ringMapCapacity, p)
+	_, ok := p.o.Front()
+	assert.False(t, ok)
+
+	m.Set(1, true)
+	m.Set(2, true)
+	m.Set(3, true)
+
+	next, ok := p.o.Next(1)
+	assert.Equal(t, 2, next)
+	assert.True(t, ok)
+	_, ok = p.o.Next(3)
+	assert.False(t, ok)
+	prev, _ := p.o.Prev(3)
+	assert.Equal(t, 2, prev)
+	_, ok = p.o.Prev(4)
+	assert.False(t, ok)
+
+	p.o.MoveToFront(3)
+	p.o.MoveToBack(1)
+	p.o.MoveToBack(4)
+	assert.Equal(t, []int{3, 2, 1}, m.Keys())
+
+	p.o.MoveBefore(1, 3)
+	assert.Equal(t, []int{1, 3, 2}, m.Keys())
+	p.o.MoveBefore(2, 3)
+	assert.Equal(t, []int{1, 2, 3}, m.Keys())
+	p.o.MoveBefore(3, 3)
+	p.o.MoveBefore(3, 4)
+	p.o.MoveBefore(4, 3)
+	assert.Equal(t, []int{1, 2, 3}, m.Keys())
+	back, _ := p.o.Back()
+	assert.Equal(t, 3, back)
+	assert.Equal(t, 3, p.o.Len())
+	assert.Equal(t, ringMapCapacity, p.o.Capacity())
+}
+
+// fifoModel is the eviction behavior RingMap had before eviction policies: a
+// full map deletes its front element when a new key is Set, and Put moves an
+// existing key to the back.
+type fifoModel struct {
+	keys     []int
+	values   map[int]int
+	capacity int
+}
+
+func (m *fifoModel) remove(key int) bool {
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			delete(m.values, key)
+			return true
+		}
+	}
+	return false
+}
+
+func (m *fifoModel) set(key, value int) bool {
+	if _, ok := m.values[key]; ok {
+		m.values[key] = value
+		return false
+	}
+	if len(m.keys) == m.capacity {
+		m.remove(m.keys[0])
+	}
+	m.keys = append(m.keys, key)
+	m.values[key] = value
+	return true
+}
+
+func (m *fifoModel) put(key, value int) bool {
+	existed := m.remove(key)
+	m.keys = append(m.keys, key)
+	m.values[key] = value
+	return !existed
+}
+
+func TestFIFOPolicy_MatchesPreviousBehavior(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	m := ringmap.NewRingMapOf[int, int](16)
+	model := &fifoModel{keys: []int{}, values: map[int]int{}, capacity: 16}
+
+	for i := 0; i < 20000; i++ {
+		key := rng.Intn(40)
+		switch rng.Intn(5) {
+		case 0, 1:
+			assert.Equal(t, model.set(key, i), m.Set(key, i))
+		case 2:
+			// The model's Put doesn't evict, so only move existing keys.
+			if _, ok := model.values[key]; ok {
+				assert.Equal(t, model.put(key, i), m.Put(key, i))
+			}
+		case 3:
+			assert.Equal(t, model.remove(key), m.Delete(key))
+		case 4:
+			value, ok := m.Get(key)
+			expected, expectedOK := model.values[key]
+			assert.Equal(t, expectedOK, ok)
+			assert.Equal(t, expected, value)
+		}
+
+		if !assert.Equal(t, model.keys, m.Keys(), fmt.Sprintf("step %d", i)) {
+			return
+		}
+	}
+}
@@ -1,104 +1,534 @@
 package ringmap
 
 import (
-	"github.com/elliotchance/orderedmap"
+	"fmt"
+	"time"
 )
 
-// RingMap the ordered map data structure
+// RingMapOf is an ordered map with a maximum capacity. Keys are of type K and
+// values of type V.
+//
+// When a new key is added to a full map, the map's EvictionPolicy picks the
+// element to delete. The default FIFOPolicy keeps the elements in insertion
+// order and evicts the oldest one, making the map a FIFO cache. A map created
+// in LRU mode uses an LRUPolicy, which also moves an element to the back
+// whenever it's read or replaced, so Front() is always the least recently used
+// element.
+//
+// Entries may also be given a time to live, after which the map treats them as
+// missing. See SetWithTTL.
+type RingMapOf[K comparable, V any] struct {
+	items    map[K]int // index of the slot of each key
+	slots    slots[K, V]
+	capacity int
+	policy   EvictionPolicy[K]
+	onEvict  EvictFunc[K, V]
+	clock    Clock
+	ttl      time.Duration
+	expiring int // number of elements with an expiry time
+
+	weigher   Weigher[K, V]
+	sizer     bool // whether values may implement Sizer
+	weight    int64
+	maxWeight int64
+
+	stats stats
+
+	keyCodec   Codec[K]
+	valueCodec Codec[V]
+}
+
+// NewRingMapOf creates a new ordered map with a maximum size that holds keys of
+// type K and values of type V. A map with zero capacity rejects every insert,
+// and a map with a negative capacity is unbounded. New validates the capacity
+// instead.
+func NewRingMapOf[K comparable, V any](capacity int) *RingMapOf[K, V] {
+	return NewRingMapWithPolicy[K, V](capacity, NewFIFOPolicy[K]())
+}
+
+// NewLRURingMapOf creates a new ordered map in LRU mode with a maximum size
+// that holds keys of type K and values of type V.
+func NewLRURingMapOf[K comparable, V any](capacity int) *RingMapOf[K, V] {
+	return NewRingMapWithPolicy[K, V](capacity, NewLRUPolicy[K]())
+}
+
+// NewRingMapWithPolicy creates a new ordered map with a maximum size that
+// evicts elements as decided by policy. A policy keeps state about the keys of
+// its map, so it must not be shared with another map.
+func NewRingMapWithPolicy[K comparable, V any](capacity int, policy EvictionPolicy[K]) *RingMapOf[K, V] {
+	m := &RingMapOf[K, V]{}
+	m.init(capacity, policy)
+
+	return m
+}
+
+// init sets up an empty map.
+func (m *RingMapOf[K, V]) init(capacity int, policy EvictionPolicy[K]) {
+	m.items = make(map[K]int)
+	m.slots = newSlots[K, V](capacity)
+	m.capacity = capacity
+	m.policy = policy
+	m.clock = systemClock{}
+	m.sizer = mayBeSizer[V]()
+	m.maxWeight = Unbounded
+	policy.Attach(ordering[K, V]{m})
+}
+
+// RingMap is an ordered map with a maximum capacity that holds keys and values
+// of any type. It wraps a RingMapOf[interface{}, interface{}] and has all of
+// its methods.
+//
+// Front and Back return an *Element, which has the Key and Value fields and the
+// Next and Prev methods of the *orderedmap.Element they returned before
+// RingMapOf, but code that names the orderedmap type has to use Element or
+// switch to FrontEntry and BackEntry.
 type RingMap struct {
-	orderedMap *orderedmap.OrderedMap
-	capacity   int
+	*RingMapOf[interface{}, interface{}]
 }
 
-// NewRingMap creates a new ordered map with a maximum size
+// NewRingMap creates a new ordered map with a maximum size that holds keys and
+// values of any type.
 func NewRingMap(capacity int) *RingMap {
-	return &RingMap{
-		orderedMap: orderedmap.NewOrderedMap(),
-		capacity:   capacity,
-	}
+	return &RingMap{NewRingMapOf[interface{}, interface{}](capacity)}
+}
+
+// NewLRURingMap creates a new ordered map in LRU mode with a maximum size that
+// holds keys and values of any type.
+func NewLRURingMap(capacity int) *RingMap {
+	return &RingMap{NewLRURingMapOf[interface{}, interface{}](capacity)}
+}
+
+// Policy returns the eviction policy of the map.
+func (m *RingMapOf[K, V]) Policy() EvictionPolicy[K] {
+	return m.policy
+}
+
+// OnEvict sets the function that is called exactly once for every entry that
+// leaves the map: when it's evicted to make room, deleted, expired, or when its
+// value is replaced by Set or Put. fn is called after the entry has been
+// removed, while the map is still being modified, so it must not use the map.
+// A nil fn removes the callback.
+func (m *RingMapOf[K, V]) OnEvict(fn EvictFunc[K, V]) {
+	m.onEvict = fn
 }
 
 // Get returns the value for a key. If the key does not exist, the second return
-// parameter will be false and the value will be nil.
-func (m *RingMap) Get(key interface{}) (interface{}, bool) {
-	return m.orderedMap.Get(key)
+// parameter will be false and the value will be the zero value of V (nil for
+// maps created with NewRingMap). A hit counts as an access for the eviction
+// policy, which moves the element to the back in LRU mode. Expired entries are
+// treated as missing.
+func (m *RingMapOf[K, V]) Get(key K) (V, bool) {
+	i, ok := m.lookup(key)
+	m.countLookup(ok)
+	if ok {
+		m.policy.OnAccess(key)
+		return m.slots.at(i).value, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Peek returns the value for a key like Get, but is not seen by the eviction
+// policy, so it never moves the element.
+func (m *RingMapOf[K, V]) Peek(key K) (V, bool) {
+	if i, ok := m.lookup(key); ok {
+		return m.slots.at(i).value, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// lookup returns the index of the slot for a key, unless it has expired.
+func (m *RingMapOf[K, V]) lookup(key K) (int, bool) {
+	i, ok := m.items[key]
+	if !ok || m.isExpired(i, time.Time{}) {
+		return none, false
+	}
+
+	return i, true
 }
 
 // Set will set (or replace) a value for a key. If the key was new, then true
 // will be returned. The returned value will be false if the value was replaced
 // (even if the value was the same).  If a new key is being added and the map is
-// full, then the front element will be deleted to make room for the new element.
-func (m *RingMap) Set(key, value interface{}) bool {
-	_, didExist := m.Get(key)
+// full, then the element chosen by the eviction policy (the front element by
+// default) will be deleted to make room for the new element. In LRU mode a
+// replaced element is moved to the back. The entry expires after the map's
+// default TTL, if it has one.
+//
+// If the new key can't be added, because the capacity is zero, the entry is
+// heavier than MaxWeight() or the eviction policy has no victim, Set returns
+// false and the map is unchanged. Use TrySet to tell that apart from a
+// replaced value.
+func (m *RingMapOf[K, V]) Set(key K, value V) bool {
+	added, _ := m.set(key, value, m.expiry(m.ttl))
+	return added
+}
+
+// TrySet is like Set, but returns an error if the key could not be added. The
+// error is ErrZeroCapacity, ErrOversized or ErrFull.
+func (m *RingMapOf[K, V]) TrySet(key K, value V) (bool, error) {
+	return m.set(key, value, m.expiry(m.ttl))
+}
+
+func (m *RingMapOf[K, V]) set(key K, value V, expires time.Time) (bool, error) {
+	weight, err := m.weigh(key, value)
+	if err != nil {
+		return false, err
+	}
 
-	if !didExist {
-		if m.IsFull() {
-			m.Delete(m.Front().Key)
+	if i, didExist := m.items[key]; didExist {
+		switch {
+		case m.isExpired(i, time.Time{}):
+			m.remove(i, EvictExpired)
+		case m.maxWeight >= 0 && m.weight-m.slots.at(i).weight+weight > m.maxWeight:
+			// The heavier value needs room. If it has to come from this very
+			// element, the element goes back in like a Put.
+			self, err := m.evictForReplace(i, key, weight)
+			if err != nil {
+				return false, err
+			}
+			if self {
+				if err := m.reinsert(i, key, value, expires, weight); err != nil {
+					return false, err
+				}
+				m.countWrite(false)
+				return false, nil
+			}
+			fallthrough
+		default:
+			s := m.slots.at(i)
+			old := s.value
+			s.value = value
+			m.setExpiry(i, expires)
+			m.setWeight(i, weight)
+			m.policy.OnUpdate(key)
+			m.notify(key, old, EvictReplaced)
+			m.countWrite(false)
+			return false, nil
 		}
 	}
-	m.orderedMap.Set(key, value)
 
-	return !didExist
+	if err := m.insert(key, value, expires, weight); err != nil {
+		return false, err
+	}
+	m.countWrite(true)
+
+	return true, nil
 }
 
 // Put will set a value for a key. If the key already exists, it will be deleted
 // from and a recreated at the end of the list.  If the key was new, then true
 // will be returned. The returned value will be false if the value was replaced
 // (even if the value was the same).  If a new key is being added and the map is
-// full, then the front element will be deleted to make room for the new element.
-func (m *RingMap) Put(key, value interface{}) bool {
-	_, didExist := m.Get(key)
+// full, then the element chosen by the eviction policy (the front element by
+// default) will be deleted to make room for the new element, just like Set.
+// The entry expires after the map's default TTL, if it has one. Like Set, Put
+// returns false if a new key can't be added.
+func (m *RingMapOf[K, V]) Put(key K, value V) bool {
+	added, _ := m.put(key, value, m.expiry(m.ttl))
+	return added
+}
+
+// TryPut is like Put, but returns an error if the key could not be added. The
+// error is ErrZeroCapacity, ErrOversized or ErrFull.
+func (m *RingMapOf[K, V]) TryPut(key K, value V) (bool, error) {
+	return m.put(key, value, m.expiry(m.ttl))
+}
+
+func (m *RingMapOf[K, V]) put(key K, value V, expires time.Time) (bool, error) {
+	weight, err := m.weigh(key, value)
+	if err != nil {
+		return false, err
+	}
+
+	i, didExist := m.items[key]
+	if didExist && !m.isExpired(i, time.Time{}) {
+		if _, err := m.evictForReplace(i, key, weight); err != nil {
+			return false, err
+		}
+		if err := m.reinsert(i, key, value, expires, weight); err != nil {
+			return false, err
+		}
+		m.countWrite(false)
+		return false, nil
+	}
 
 	if didExist {
-		m.Delete(key)
+		m.remove(i, EvictExpired)
+	}
+	if err := m.insert(key, value, expires, weight); err != nil {
+		return false, err
+	}
+	m.countWrite(true)
+
+	return true, nil
+}
+
+// evictForReplace evicts other elements until the element of key, in the slot
+// at index i, can be replaced by one of the given weight. It stops early and
+// returns true if the eviction policy picks that element itself, and returns
+// ErrFull if the policy runs out of victims, leaving the element in place.
+func (m *RingMapOf[K, V]) evictForReplace(i int, key K, weight int64) (bool, error) {
+	if m.maxWeight < 0 || m.weight-m.slots.at(i).weight+weight <= m.maxWeight {
+		return false, nil
+	}
+
+	m.purgeFront()
+	for m.weight-m.slots.at(i).weight+weight > m.maxWeight {
+		victim, ok := m.policy.Victim()
+		if !ok {
+			return false, ErrFull
+		}
+		if victim == key {
+			return true, nil
+		}
+
+		j, ok := m.items[victim]
+		if !ok {
+			return false, ErrFull
+		}
+		m.remove(j, EvictCapacity)
+	}
+
+	return false, nil
+}
+
+// reinsert replaces the element of key, in the slot at index i, with a new
+// element at the back. If the new element can't be added, the old one is put
+// back, which always fits, so the key keeps its old value.
+func (m *RingMapOf[K, V]) reinsert(i int, key K, value V, expires time.Time, weight int64) error {
+	s := m.slots.at(i)
+	oldValue, oldExpires, oldWeight := s.value, s.expires, s.weight
+	m.remove(i, EvictReplaced)
+	if err := m.insert(key, value, expires, weight); err != nil {
+		m.insert(key, oldValue, oldExpires, oldWeight)
+		return err
+	}
+
+	return nil
+}
+
+// insert adds a new key at the back of the list. Every insert goes through
+// here, so this is where the capacity and the maximum weight are enforced.
+func (m *RingMapOf[K, V]) insert(key K, value V, expires time.Time, weight int64) error {
+	if m.capacity == 0 {
+		return ErrZeroCapacity
+	}
+	if p, ok := m.policy.(CandidatePolicy[K]); ok {
+		p.OnCandidate(key)
+	}
+	if err := m.evictFor(1, weight); err != nil {
+		return err
+	}
+
+	i := m.slots.pushBack(key, value)
+	m.setExpiry(i, expires)
+	m.setWeight(i, weight)
+	m.items[key] = i
+	m.policy.OnInsert(key)
+
+	return nil
+}
+
+// evictFor evicts elements until n more elements with a total weight of weight
+// fit in the map. Expired elements at the front are deleted first; if that
+// doesn't free enough, victims chosen by the eviction policy are deleted. It
+// returns ErrFull if the eviction policy runs out of victims.
+func (m *RingMapOf[K, V]) evictFor(n int, weight int64) error {
+	if m.fits(n, weight) {
+		return nil
+	}
+
+	m.purgeFront()
+	for !m.fits(n, weight) {
+		if !m.evict() {
+			return ErrFull
+		}
 	}
-	m.orderedMap.Set(key, value)
 
-	return !didExist
+	return nil
+}
+
+// fits returns true if n more elements with a total weight of weight fit in
+// the map without evicting anything.
+func (m *RingMapOf[K, V]) fits(n int, weight int64) bool {
+	return (m.capacity < 0 || len(m.items)+n <= m.capacity) &&
+		(m.maxWeight < 0 || m.weight+weight <= m.maxWeight)
+}
+
+// Resize changes the capacity of the map. Shrinking evicts elements, chosen
+// like they are to make room for a new key, until the map fits; each one is
+// reported to the eviction callback with EvictCapacity. Growing takes effect
+// immediately and allocates the added slots without copying the existing ones.
+// Shrinking keeps the slots, so it doesn't free memory.
+//
+// The capacity follows the rules of WithCapacity: zero rejects every insert,
+// Unbounded never evicts, and any other negative capacity returns
+// ErrInvalidCapacity without changing the map. If the eviction policy runs out
+// of victims while shrinking, Resize returns ErrFull and the map keeps its
+// remaining elements.
+func (m *RingMapOf[K, V]) Resize(capacity int) error {
+	if capacity < 0 && capacity != Unbounded {
+		return fmt.Errorf("%w: %d", ErrInvalidCapacity, capacity)
+	}
+
+	m.capacity = capacity
+	m.slots.grow(capacity)
+
+	return m.evictFor(0, 0)
+}
+
+// evict deletes the victim chosen by the eviction policy. It returns false if
+// the policy has no victim.
+func (m *RingMapOf[K, V]) evict() bool {
+	key, ok := m.policy.Victim()
+	if !ok {
+		return false
+	}
+
+	i, ok := m.items[key]
+	if !ok {
+		return false
+	}
+	m.remove(i, EvictCapacity)
+
+	return true
+}
+
+// remove takes the entry in the slot at index i out of the map and reports it
+// as removed for reason.
+func (m *RingMapOf[K, V]) remove(i int, reason EvictReason) {
+	m.setExpiry(i, time.Time{})
+	m.setWeight(i, 0)
+	s := m.slots.at(i)
+	key, value := s.key, s.value
+	m.slots.remove(i)
+	delete(m.items, key)
+	m.policy.OnDelete(key)
+	m.notify(key, value, reason)
+}
+
+// notify counts an entry that left the map and calls the eviction callback, if
+// there is one.
+func (m *RingMapOf[K, V]) notify(key K, value V, reason EvictReason) {
+	m.countEviction(reason)
+	if m.onEvict != nil {
+		m.onEvict(key, value, reason)
+	}
 }
 
 // GetOrDefault returns the value for a key. If the key does not exist, returns
-// the default value instead.
-func (m *RingMap) GetOrDefault(key, defaultValue interface{}) interface{} {
-	return m.orderedMap.GetOrDefault(key, defaultValue)
+// the default value instead. Like Get, a hit counts as an access.
+func (m *RingMapOf[K, V]) GetOrDefault(key K, defaultValue V) V {
+	i, ok := m.lookup(key)
+	m.countLookup(ok)
+	if ok {
+		m.policy.OnAccess(key)
+		return m.slots.at(i).value
+	}
+
+	return defaultValue
 }
 
-// Len returns the number of elements in the map.
-func (m *RingMap) Len() int {
-	return m.orderedMap.Len()
+// Len returns the number of elements in the map, not counting expired ones.
+// While any element has an expiry time, Len has to visit every element.
+func (m *RingMapOf[K, V]) Len() int {
+	if m.expiring == 0 {
+		return len(m.items)
+	}
+
+	n := 0
+	now := m.clock.Now()
+	for i := m.slots.front; i != none; i = m.slots.at(i).next {
+		if !m.isExpired(i, now) {
+			n++
+		}
+	}
+
+	return n
 }
 
-// Capacity returns the capacity of the map
-func (m *RingMap) Capacity() int {
+// Capacity returns the capacity of the map, or a negative number (Unbounded for
+// maps created with New) if the map is unbounded.
+func (m *RingMapOf[K, V]) Capacity() int {
 	return m.capacity
 }
 
-// IsFull returns true if the number of elements in the map is Capacity()
-func (m *RingMap) IsFull() bool {
-	return m.orderedMap.Len() == m.capacity
+// IsFull returns true if the number of elements in the map has reached
+// Capacity(). A map with a negative capacity is never full.
+func (m *RingMapOf[K, V]) IsFull() bool {
+	return m.capacity >= 0 && m.Len() >= m.capacity
 }
 
 // Keys returns all of the keys in the order they were inserted. If a key was
 // replaced it will retain the same position. To ensure most recently set keys
-// are always at the end you must always Delete before Set.
-func (m *RingMap) Keys() (keys []interface{}) {
-	return m.orderedMap.Keys()
+// are always at the end you must always Delete before Set. Eviction policies
+// may reorder the keys; in LRU mode they are ordered from least to most
+// recently used. Expired keys are left out.
+func (m *RingMapOf[K, V]) Keys() (keys []K) {
+	keys = make([]K, 0, len(m.items))
+	m.rangeSlots(m.slots.front, true, func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+
+	return keys
+}
+
+// rangeSlots calls f for the key and value of every entry that hasn't expired,
+// from the slot at index i on towards the back if forward is true and towards
+// the front otherwise, until f returns false. It reads the list without
+// changing it, so f must not change the map.
+func (m *RingMapOf[K, V]) rangeSlots(i int, forward bool, f func(key K, value V) bool) {
+	now := m.clock.Now()
+	for ; i != none; i = m.slots.step(i, forward) {
+		if m.isExpired(i, now) {
+			continue
+		}
+		if s := m.slots.at(i); !f(s.key, s.value) {
+			return
+		}
+	}
 }
 
 // Delete will remove a key from the map. It will return true if the key was
-// removed (the key did exist).
-func (m *RingMap) Delete(key interface{}) (didDelete bool) {
-	return m.orderedMap.Delete(key)
+// removed (the key did exist). An expired key is removed as expired, and
+// Delete returns false for it like Get does.
+func (m *RingMapOf[K, V]) Delete(key K) (didDelete bool) {
+	i, ok := m.items[key]
+	if !ok {
+		return false
+	}
+	if m.isExpired(i, time.Time{}) {
+		m.remove(i, EvictExpired)
+		return false
+	}
+	m.remove(i, EvictDeleted)
+
+	return true
 }
 
-// Front will return the element that is the first (oldest Set element). If
-// there are no elements this will return nil.
-func (m *RingMap) Front() *orderedmap.Element {
-	return m.orderedMap.Front()
+// Front will return the element that is the first (oldest Set element, or the
+// least recently used one in LRU mode). With the FIFO and LRU policies it is
+// the next element to be evicted; see EvictionPolicy for the others. Expired
+// elements are included until they are removed. If there are no elements this
+// will return nil.
+//
+// Deprecated: Use FrontEntry, which can't be used to change the map behind its
+// back. Front and Element will be removed in the next release.
+func (m *RingMapOf[K, V]) Front() *Element[K, V] {
+	return m.element(m.slots.front)
 }
 
-// Back will return the element that is the last (most recent Set element). If
-// there are no elements this will return nil.
-func (m *RingMap) Back() *orderedmap.Element {
-	return m.orderedMap.Back()
+// Back will return the element that is the last (most recent Set element, or
+// the most recently used one in LRU mode). Expired elements are included until
+// they are removed. If there are no elements this will return nil.
+//
+// Deprecated: Use BackEntry, which can't be used to change the map behind its
+// back. Back and Element will be removed in the next release.
+func (m *RingMapOf[K, V]) Back() *Element[K, V] {
+	return m.element(m.slots.back)
 }
@@ -2,8 +2,10 @@ package ringmap_test
 
 import (
 	"fmt"
+	"math/rand"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/prgsmall/ringmap"
 	"github.com/stretchr/testify/assert"
@@ -13,6 +15,13 @@ var ringMapCapacity = 777
 
 func TestObjectCreation(t *testing.T) {
 
+	t.Run("TestNewRingMapOf", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		assert.IsType(t, &ringmap.RingMapOf[string, int]{}, m)
+		assert.Equal(t, ringMapCapacity, m.Capacity())
+		assert.EqualValues(t, false, m.IsFull())
+	})
+
 	t.Run("TestNewRingMap", func(t *testing.T) {
 		m := ringmap.NewRingMap(ringMapCapacity)
 		assert.IsType(t, &ringmap.RingMap{}, m)
@@ -21,83 +30,134 @@ func TestObjectCreation(t *testing.T) {
 	})
 }
 
+func TestUntyped(t *testing.T) {
+	t.Run("MixedKeysAndValues", func(t *testing.T) {
+		m := ringmap.NewRingMap(ringMapCapacity)
+		m.Set("foo", "bar")
+		m.Set(123, true)
+		m.Put(1.5, nil)
+		assert.Equal(t, []interface{}{"foo", 123, 1.5}, m.Keys())
+		assert.Equal(t, "foo", m.Front().Key)
+		assert.Equal(t, 1.5, m.Back().Key)
+	})
+
+	t.Run("ElementWalk", func(t *testing.T) {
+		// Code written before RingMapOf declares the map and walks its
+		// elements like this.
+		var m *ringmap.RingMap = ringmap.NewLRURingMap(ringMapCapacity)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		var keys []interface{}
+		for el := m.Front(); el != nil; el = el.Next() {
+			keys = append(keys, el.Key)
+		}
+		assert.Equal(t, []interface{}{"a", "b"}, keys)
+	})
+
+	t.Run("MissingKeyIsNil", func(t *testing.T) {
+		m := ringmap.NewRingMap(ringMapCapacity)
+		value, ok := m.Get("foo")
+		assert.False(t, ok)
+		assert.Nil(t, value)
+	})
+}
+
 func TestGet(t *testing.T) {
 	t.Run("ReturnsNotOKIfStringKeyDoesntExist", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		_, ok := m.Get("foo")
 		assert.False(t, ok)
 	})
 
 	t.Run("ReturnsNotOKIfNonStringKeyDoesntExist", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, string](ringMapCapacity)
 		_, ok := m.Get(123)
 		assert.False(t, ok)
 	})
 
 	t.Run("ReturnsOKIfKeyExists", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		m.Set("foo", "bar")
 		_, ok := m.Get("foo")
 		assert.True(t, ok)
 	})
 
 	t.Run("ReturnsValueForKey", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		m.Set("foo", "bar")
 		value, _ := m.Get("foo")
 		assert.Equal(t, "bar", value)
 	})
 
 	t.Run("ReturnsDynamicValueForKey", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		m.Set("foo", "baz")
 		value, _ := m.Get("foo")
 		assert.Equal(t, "baz", value)
 	})
 
 	t.Run("KeyDoesntExistOnNonEmptyMap", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		m.Set("foo", "baz")
 		_, ok := m.Get("bar")
 		assert.False(t, ok)
 	})
 
 	t.Run("ValueForKeyDoesntExistOnNonEmptyMap", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		m.Set("foo", "baz")
 		value, _ := m.Get("bar")
+		assert.Empty(t, value)
+	})
+
+	t.Run("ValueForKeyDoesntExistIsNilPointer", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, *int](ringMapCapacity)
+		value, _ := m.Get("bar")
 		assert.Nil(t, value)
 	})
 }
 
+func TestGetOrDefault(t *testing.T) {
+	t.Run("ReturnsDefaultIfKeyDoesntExist", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		assert.Equal(t, 42, m.GetOrDefault("foo", 42))
+	})
+
+	t.Run("ReturnsValueIfKeyExists", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.Set("foo", 7)
+		assert.Equal(t, 7, m.GetOrDefault("foo", 42))
+	})
+}
+
 func TestPut(t *testing.T) {
 	t.Run("ReturnsTrueIfStringKeyIsNew", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		ok := m.Put("foo", "bar")
 		assert.True(t, ok)
 	})
 
 	t.Run("ReturnsTrueIfNonStringKeyIsNew", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, string](ringMapCapacity)
 		ok := m.Put(123, "bar")
 		assert.True(t, ok)
 	})
 
 	t.Run("ValueCanBeNonString", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		ok := m.Put(123, true)
 		assert.True(t, ok)
 	})
 
 	t.Run("ReturnsFalseIfKeyIsNotNew", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		m.Put("foo", "bar")
 		ok := m.Put("foo", "bar")
 		assert.False(t, ok)
 	})
 
 	t.Run("PutMultipleKeys", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		m.Put("foo", "bar")
 		m.Put("baz", "qux")
 		m.Put("mik", "qux")
@@ -106,7 +166,7 @@ func TestPut(t *testing.T) {
 	})
 
 	t.Run("PutMultipleDifferentKeysWithReplace", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		m.Put("foo", "bar")
 		m.Put("baz", "baz")
 		m.Put("mik", "mik")
@@ -114,37 +174,38 @@ func TestPut(t *testing.T) {
 		assert.False(t, ok)
 		assert.Equal(t, "baz", m.Front().Key)
 		assert.Equal(t, "foo", m.Back().Key)
+		assert.Equal(t, "corge", m.Back().Value)
 	})
 }
 
 func TestSet(t *testing.T) {
 	t.Run("ReturnsTrueIfStringKeyIsNew", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		ok := m.Set("foo", "bar")
 		assert.True(t, ok)
 	})
 
 	t.Run("ReturnsTrueIfNonStringKeyIsNew", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, string](ringMapCapacity)
 		ok := m.Set(123, "bar")
 		assert.True(t, ok)
 	})
 
 	t.Run("ValueCanBeNonString", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		ok := m.Set(123, true)
 		assert.True(t, ok)
 	})
 
 	t.Run("ReturnsFalseIfKeyIsNotNew", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		m.Set("foo", "bar")
 		ok := m.Set("foo", "bar")
 		assert.False(t, ok)
 	})
 
 	t.Run("SetThreeDifferentKeys", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, string](ringMapCapacity)
 		m.Set("foo", "bar")
 		m.Set("baz", "qux")
 		ok := m.Set("quux", "corge")
@@ -154,18 +215,18 @@ func TestSet(t *testing.T) {
 
 func TestLen(t *testing.T) {
 	t.Run("EmptyMapIsZeroLen", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		assert.Equal(t, 0, m.Len())
 	})
 
 	t.Run("SingleElementIsLenOne", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		m.Set(123, true)
 		assert.Equal(t, 1, m.Len())
 	})
 
 	t.Run("ThreeElements", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		m.Set(1, true)
 		m.Set(2, true)
 		m.Set(3, true)
@@ -173,7 +234,7 @@ func TestLen(t *testing.T) {
 	})
 
 	t.Run("ThreeElementsWithMax", func(t *testing.T) {
-		m := ringmap.NewRingMap(3)
+		m := ringmap.NewRingMapOf[int, bool](3)
 		assert.Equal(t, false, m.IsFull())
 		m.Set(1, true)
 		assert.Equal(t, false, m.IsFull())
@@ -194,59 +255,59 @@ func TestLen(t *testing.T) {
 
 func TestKeys(t *testing.T) {
 	t.Run("EmptyMap", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		assert.Empty(t, m.Keys())
 	})
 
 	t.Run("OneElement", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		m.Set(1, true)
-		assert.Equal(t, []interface{}{1}, m.Keys())
+		assert.Equal(t, []int{1}, m.Keys())
 	})
 
 	t.Run("RetainsOrder", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		for i := 1; i < 10; i++ {
 			m.Set(i, true)
 		}
 		assert.Equal(t,
-			[]interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9},
+			[]int{1, 2, 3, 4, 5, 6, 7, 8, 9},
 			m.Keys())
 	})
 
 	t.Run("ReplacingKeyDoesntChangeOrder", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, bool](ringMapCapacity)
 		m.Set("foo", true)
 		m.Set("bar", true)
 		m.Set("foo", false)
 		assert.Equal(t,
-			[]interface{}{"foo", "bar"},
+			[]string{"foo", "bar"},
 			m.Keys())
 	})
 
 	t.Run("KeysAfterDelete", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, bool](ringMapCapacity)
 		m.Set("foo", true)
 		m.Set("bar", true)
 		m.Delete("foo")
-		assert.Equal(t, []interface{}{"bar"}, m.Keys())
+		assert.Equal(t, []string{"bar"}, m.Keys())
 	})
 }
 
 func TestDelete(t *testing.T) {
 	t.Run("KeyDoesntExistReturnsFalse", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, *int](ringMapCapacity)
 		assert.False(t, m.Delete("foo"))
 	})
 
 	t.Run("KeyDoesExist", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, *int](ringMapCapacity)
 		m.Set("foo", nil)
 		assert.True(t, m.Delete("foo"))
 	})
 
 	t.Run("KeyNoLongerExists", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, *int](ringMapCapacity)
 		m.Set("foo", nil)
 		m.Delete("foo")
 		_, exists := m.Get("foo")
@@ -254,23 +315,33 @@ func TestDelete(t *testing.T) {
 	})
 
 	t.Run("KeyDeleteIsIsolated", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, *int](ringMapCapacity)
 		m.Set("foo", nil)
 		m.Set("bar", nil)
 		m.Delete("foo")
 		_, exists := m.Get("bar")
 		assert.True(t, exists)
 	})
+
+	t.Run("DeleteKeepsOrderOfNeighbours", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
+		m.Set(1, true)
+		m.Set(2, true)
+		m.Set(3, true)
+		m.Delete(2)
+		assert.Equal(t, 3, m.Front().Next().Key)
+		assert.Equal(t, 1, m.Back().Prev().Key)
+	})
 }
 
 func TestRingMap_Front(t *testing.T) {
 	t.Run("NilOnEmptyMap", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		assert.Nil(t, m.Front())
 	})
 
 	t.Run("NilOnEmptyMap", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		m.Set(1, true)
 		assert.NotNil(t, m.Front())
 	})
@@ -278,17 +349,298 @@ func TestRingMap_Front(t *testing.T) {
 
 func TestRingMap_Back(t *testing.T) {
 	t.Run("NilOnEmptyMap", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		assert.Nil(t, m.Back())
 	})
 
 	t.Run("NilOnEmptyMap", func(t *testing.T) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		m.Set(1, true)
 		assert.NotNil(t, m.Back())
 	})
 }
 
+func TestCapacity(t *testing.T) {
+	t.Run("PutEvictsWhenFull", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, bool](3)
+		for i := 0; i < 10; i++ {
+			assert.True(t, m.Put(i, true))
+		}
+		assert.Equal(t, 3, m.Len())
+		assert.True(t, m.IsFull())
+		assert.Equal(t, []int{7, 8, 9}, m.Keys())
+	})
+
+	t.Run("PutOfExistingKeyDoesntEvict", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, bool](3)
+		m.Put(1, true)
+		m.Put(2, true)
+		m.Put(3, true)
+		m.Put(1, false)
+		assert.Equal(t, []int{2, 3, 1}, m.Keys())
+	})
+
+	t.Run("NegativeCapacityIsNeverFull", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, bool](-1)
+		for i := 0; i < 100; i++ {
+			m.Set(i, true)
+			m.Put(-i, true)
+		}
+		assert.Equal(t, 199, m.Len())
+		assert.False(t, m.IsFull())
+	})
+
+	t.Run("LenNeverExceedsCapacity", func(t *testing.T) {
+		policies := map[string]func() ringmap.EvictionPolicy[int]{
+			"FIFO": func() ringmap.EvictionPolicy[int] { return ringmap.NewFIFOPolicy[int]() },
+			"LRU":  func() ringmap.EvictionPolicy[int] { return ringmap.NewLRUPolicy[int]() },
+		}
+		for name, policy := range policies {
+			for _, capacity := range []int{1, 2, 7, 64} {
+				rng := rand.New(rand.NewSource(int64(capacity)))
+				m := ringmap.NewRingMapWithPolicy[int, int](capacity, policy())
+				for i := 0; i < 5000; i++ {
+					key := rng.Intn(capacity * 3)
+					switch rng.Intn(6) {
+					case 0:
+						m.Set(key, i)
+					case 1:
+						m.Put(key, i)
+					case 2:
+						m.SetWithTTL(key, i, time.Nanosecond)
+					case 3:
+						m.PutWithTTL(key, i, time.Hour)
+					case 4:
+						m.Delete(key)
+					case 5:
+						m.Get(key)
+					}
+
+					if !assert.True(t, m.Len() <= m.Capacity(), "%s capacity %d step %d", name, capacity, i) {
+						break
+					}
+					assert.True(t, len(m.Keys()) <= m.Capacity())
+				}
+			}
+		}
+	})
+}
+
+func TestResize(t *testing.T) {
+	t.Run("ShrinkEvictsFromFront", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, bool](5)
+		var evicted []int
+		m.OnEvict(func(key int, _ bool, reason ringmap.EvictReason) {
+			assert.Equal(t, ringmap.EvictCapacity, reason)
+			evicted = append(evicted, key)
+		})
+		for i := 1; i <= 5; i++ {
+			m.Set(i, true)
+		}
+
+		assert.NoError(t, m.Resize(2))
+		assert.Equal(t, 2, m.Capacity())
+		assert.Equal(t, []int{4, 5}, m.Keys())
+		assert.Equal(t, []int{1, 2, 3}, evicted)
+
+		m.Set(6, true)
+		assert.Equal(t, []int{5, 6}, m.Keys())
+	})
+
+	t.Run("ShrinkFollowsPolicy", func(t *testing.T) {
+		m := ringmap.NewLRURingMapOf[int, bool](3)
+		m.Set(1, true)
+		m.Set(2, true)
+		m.Set(3, true)
+		m.Get(1)
+		assert.NoError(t, m.Resize(1))
+		assert.Equal(t, []int{1}, m.Keys())
+	})
+
+	t.Run("ShrinkDropsExpiredFirst", func(t *testing.T) {
+		m, clock := newTTLMap(3)
+		evictions := recordEvictions(m)
+		m.SetWithTTL("a", 1, time.Second)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		clock.Advance(time.Second)
+
+		assert.NoError(t, m.Resize(1))
+		assert.Equal(t, []eviction{
+			{"a", 1, ringmap.EvictExpired},
+			{"b", 2, ringmap.EvictCapacity},
+		}, *evictions)
+	})
+
+	t.Run("GrowKeepsElements", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, bool](2)
+		m.Set(1, true)
+		m.Set(2, true)
+		assert.NoError(t, m.Resize(4))
+		assert.False(t, m.IsFull())
+		m.Set(3, true)
+		m.Set(4, true)
+		assert.Equal(t, []int{1, 2, 3, 4}, m.Keys())
+	})
+
+	t.Run("ToZero", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, bool](2)
+		m.Set(1, true)
+		assert.NoError(t, m.Resize(0))
+		assert.Equal(t, 0, m.Len())
+		assert.False(t, m.Set(2, true))
+	})
+
+	t.Run("ToUnbounded", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, bool](2)
+		assert.NoError(t, m.Resize(ringmap.Unbounded))
+		for i := 0; i < 10; i++ {
+			m.Set(i, true)
+		}
+		assert.Equal(t, 10, m.Len())
+	})
+
+	t.Run("InvalidCapacity", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, bool](2)
+		assert.ErrorIs(t, m.Resize(-2), ringmap.ErrInvalidCapacity)
+		assert.Equal(t, 2, m.Capacity())
+	})
+
+	t.Run("PolicyWithoutVictim", func(t *testing.T) {
+		m := ringmap.NewRingMapWithPolicy[int, bool](2, &pinnedPolicy{})
+		m.Set(1, true)
+		m.Set(2, true)
+		assert.ErrorIs(t, m.Resize(1), ringmap.ErrFull)
+		assert.Equal(t, 2, m.Len())
+	})
+
+	t.Run("SyncRingMap", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[int, bool](3)
+		m.Set(1, true)
+		m.Set(2, true)
+		assert.NoError(t, m.Resize(1))
+		assert.Equal(t, []int{2}, m.Keys())
+	})
+
+	t.Run("ShardedRingMap", func(t *testing.T) {
+		m := ringmap.NewShardedRingMap[int, bool](100, 4)
+		for i := 0; i < 100; i++ {
+			m.Set(i, true)
+		}
+		assert.NoError(t, m.Resize(10))
+		assert.Equal(t, 10, m.Capacity())
+		assert.True(t, m.Len() <= 10)
+
+		assert.NoError(t, m.Resize(ringmap.Unbounded))
+		assert.Equal(t, ringmap.Unbounded, m.Capacity())
+		assert.ErrorIs(t, m.Resize(-3), ringmap.ErrInvalidCapacity)
+	})
+}
+
+func TestLRU(t *testing.T) {
+	t.Run("FIFOModeByDefault", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
+		assert.IsType(t, &ringmap.FIFOPolicy[int]{}, m.Policy())
+		assert.IsType(t, &ringmap.LRUPolicy[int]{}, ringmap.NewLRURingMapOf[int, bool](ringMapCapacity).Policy())
+		assert.IsType(t, &ringmap.LRUPolicy[interface{}]{}, ringmap.NewLRURingMap(ringMapCapacity).Policy())
+	})
+
+	t.Run("GetDoesntReorderInFIFOMode", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, bool](3)
+		m.Set(1, true)
+		m.Set(2, true)
+		m.Set(3, true)
+		m.Get(1)
+		m.Set(4, true)
+		assert.Equal(t, []int{2, 3, 4}, m.Keys())
+	})
+
+	t.Run("GetPromotesToBack", func(t *testing.T) {
+		m := ringmap.NewLRURingMapOf[int, bool](ringMapCapacity)
+		m.Set(1, true)
+		m.Set(2, true)
+		m.Set(3, true)
+		m.Get(1)
+		assert.Equal(t, 2, m.Front().Key)
+		assert.Equal(t, 1, m.Back().Key)
+	})
+
+	t.Run("GetOrDefaultPromotesToBack", func(t *testing.T) {
+		m := ringmap.NewLRURingMapOf[int, bool](ringMapCapacity)
+		m.Set(1, true)
+		m.Set(2, true)
+		m.GetOrDefault(1, false)
+		assert.Equal(t, []int{2, 1}, m.Keys())
+	})
+
+	t.Run("MissDoesntReorder", func(t *testing.T) {
+		m := ringmap.NewLRURingMapOf[int, bool](ringMapCapacity)
+		m.Set(1, true)
+		m.Set(2, true)
+		m.Get(3)
+		assert.Equal(t, []int{1, 2}, m.Keys())
+	})
+
+	t.Run("SetOfExistingKeyPromotesToBack", func(t *testing.T) {
+		m := ringmap.NewLRURingMapOf[int, bool](ringMapCapacity)
+		m.Set(1, true)
+		m.Set(2, true)
+		assert.False(t, m.Set(1, false))
+		assert.Equal(t, []int{2, 1}, m.Keys())
+	})
+
+	t.Run("EvictsLeastRecentlyUsed", func(t *testing.T) {
+		m := ringmap.NewLRURingMapOf[int, bool](3)
+		m.Set(1, true)
+		m.Set(2, true)
+		m.Set(3, true)
+		m.Get(1)
+		m.Get(2)
+		assert.Equal(t, 3, m.Front().Key)
+
+		m.Set(4, true)
+		_, ok := m.Peek(3)
+		assert.False(t, ok)
+		assert.Equal(t, []int{1, 2, 4}, m.Keys())
+	})
+
+	t.Run("MixedReadsAndWrites", func(t *testing.T) {
+		m := ringmap.NewLRURingMapOf[string, int](3)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Get("a")     // b, a
+		m.Set("c", 3)  // b, a, c
+		m.Put("b", 20) // a, c, b
+		m.Get("a")     // c, b, a
+		m.Set("d", 4)  // evicts c
+		assert.Equal(t, "b", m.Front().Key)
+		m.Set("e", 5) // evicts b
+		assert.Equal(t, "a", m.Front().Key)
+		m.Get("a")    // d, e, a
+		m.Set("f", 6) // evicts d
+		assert.Equal(t, []string{"e", "a", "f"}, m.Keys())
+	})
+}
+
+func TestPeek(t *testing.T) {
+	t.Run("ReturnsValueWithoutPromoting", func(t *testing.T) {
+		m := ringmap.NewLRURingMapOf[int, string](ringMapCapacity)
+		m.Set(1, "a")
+		m.Set(2, "b")
+		value, ok := m.Peek(1)
+		assert.True(t, ok)
+		assert.Equal(t, "a", value)
+		assert.Equal(t, 1, m.Front().Key)
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		m := ringmap.NewLRURingMapOf[int, string](ringMapCapacity)
+		value, ok := m.Peek(1)
+		assert.False(t, ok)
+		assert.Equal(t, "", value)
+	})
+}
+
 func benchmarkMap_Set(multiplier int) func(b *testing.B) {
 	return func(b *testing.B) {
 		m := make(map[int]bool)
@@ -304,7 +656,7 @@ func BenchmarkMap_Set(b *testing.B) {
 
 func benchmarkRingMap_Set(multiplier int) func(b *testing.B) {
 	return func(b *testing.B) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		for i := 0; i < b.N*multiplier; i++ {
 			m.Set(i, true)
 		}
@@ -333,7 +685,7 @@ func BenchmarkMap_Get(b *testing.B) {
 }
 
 func benchmarkRingMap_Get(multiplier int) func(b *testing.B) {
-	m := ringmap.NewRingMap(ringMapCapacity)
+	m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 	for i := 0; i < 1000*multiplier; i++ {
 		m.Set(i, true)
 	}
@@ -349,11 +701,103 @@ func BenchmarkRingMap_Get(b *testing.B) {
 	benchmarkRingMap_Get(1)(b)
 }
 
+// The parallel benchmarks compare a single lock against sharded locks. Run them
+// with -cpu 1,2,4,8 to see how each scales with GOMAXPROCS.
+
+func benchmarkSyncRingMap_SetParallel() func(b *testing.B) {
+	return func(b *testing.B) {
+		m := ringmap.NewSyncRingMap[int, bool](ringMapCapacity)
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				m.Set(i, true)
+			}
+		})
+	}
+}
+
+func BenchmarkSyncRingMap_SetParallel(b *testing.B) {
+	benchmarkSyncRingMap_SetParallel()(b)
+}
+
+func benchmarkShardedRingMap_SetParallel(shards int) func(b *testing.B) {
+	return func(b *testing.B) {
+		m := ringmap.NewShardedRingMap[int, bool](ringMapCapacity, shards)
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				m.Set(i, true)
+			}
+		})
+	}
+}
+
+func BenchmarkShardedRingMap_SetParallel(b *testing.B) {
+	benchmarkShardedRingMap_SetParallel(32)(b)
+}
+
+func benchmarkSyncRingMap_GetParallel() func(b *testing.B) {
+	m := ringmap.NewSyncRingMap[int, bool](ringMapCapacity)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, true)
+	}
+
+	return func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				m.Get(i % 1000)
+			}
+		})
+	}
+}
+
+func BenchmarkSyncRingMap_GetParallel(b *testing.B) {
+	benchmarkSyncRingMap_GetParallel()(b)
+}
+
+func benchmarkShardedRingMap_GetParallel(shards int) func(b *testing.B) {
+	m := ringmap.NewShardedRingMap[int, bool](ringMapCapacity, shards)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, true)
+	}
+
+	return func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				m.Get(i % 1000)
+			}
+		})
+	}
+}
+
+func BenchmarkShardedRingMap_GetParallel(b *testing.B) {
+	benchmarkShardedRingMap_GetParallel(32)(b)
+}
+
+func benchmarkShardedRingMap_MixedParallel(shards int) func(b *testing.B) {
+	return func(b *testing.B) {
+		m := ringmap.NewShardedRingMap[int, bool](ringMapCapacity, shards)
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				if i%4 == 0 {
+					m.Set(i%2000, true)
+				} else {
+					m.Get(i % 2000)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkShardedRingMap_MixedParallel(b *testing.B) {
+	for _, shards := range []int{1, 4, 16, 64} {
+		b.Run("Shards"+strconv.Itoa(shards), benchmarkShardedRingMap_MixedParallel(shards))
+	}
+}
+
 // prevent compiler from optimising Len away.
 var tempInt int
 
 func benchmarkRingMap_Len(multiplier int) func(b *testing.B) {
-	m := ringmap.NewRingMap(ringMapCapacity)
+	m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 	for i := 0; i < 1000*multiplier; i++ {
 		m.Set(i, true)
 	}
@@ -392,7 +836,7 @@ func BenchmarkMap_Delete(b *testing.B) {
 
 func benchmarkRingMap_Delete(multiplier int) func(b *testing.B) {
 	return func(b *testing.B) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 		for i := 0; i < b.N*multiplier; i++ {
 			m.Set(i, true)
 		}
@@ -425,7 +869,7 @@ func BenchmarkMap_Iterate(b *testing.B) {
 }
 
 func benchmarkRingMap_Iterate(multiplier int) func(b *testing.B) {
-	m := ringmap.NewRingMap(ringMapCapacity)
+	m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 	for i := 0; i < 1000*multiplier; i++ {
 		m.Set(i, true)
 	}
@@ -445,7 +889,7 @@ func BenchmarkRingMap_Iterate(b *testing.B) {
 }
 
 func benchmarkRingMap_Keys(multiplier int) func(b *testing.B) {
-	m := ringmap.NewRingMap(ringMapCapacity)
+	m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 	for i := 0; i < 1000*multiplier; i++ {
 		m.Set(i, true)
 	}
@@ -473,7 +917,7 @@ func BenchmarkMapString_Set(b *testing.B) {
 
 func benchmarkRingMapString_Set(multiplier int) func(b *testing.B) {
 	return func(b *testing.B) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, bool](ringMapCapacity)
 		a := "12345678"
 		for i := 0; i < b.N*multiplier; i++ {
 			m.Set(a+strconv.Itoa(i), true)
@@ -504,7 +948,7 @@ func BenchmarkMapString_Get(b *testing.B) {
 }
 
 func benchmarkRingMapString_Get(multiplier int) func(b *testing.B) {
-	m := ringmap.NewRingMap(ringMapCapacity)
+	m := ringmap.NewRingMapOf[string, bool](ringMapCapacity)
 	a := "12345678"
 	for i := 0; i < 1000*multiplier; i++ {
 		m.Set(a+strconv.Itoa(i), true)
@@ -541,7 +985,7 @@ func BenchmarkMapString_Delete(b *testing.B) {
 
 func benchmarkRingMapString_Delete(multiplier int) func(b *testing.B) {
 	return func(b *testing.B) {
-		m := ringmap.NewRingMap(ringMapCapacity)
+		m := ringmap.NewRingMapOf[string, bool](ringMapCapacity)
 		a := "12345678"
 		for i := 0; i < b.N*multiplier; i++ {
 			m.Set(a+strconv.Itoa(i), true)
@@ -576,7 +1020,7 @@ func BenchmarkMapString_Iterate(b *testing.B) {
 }
 
 func benchmarkRingMapString_Iterate(multiplier int) func(b *testing.B) {
-	m := ringmap.NewRingMap(ringMapCapacity)
+	m := ringmap.NewRingMapOf[string, bool](ringMapCapacity)
 	a := "12345678"
 	for i := 0; i < 1000*multiplier; i++ {
 		m.Set(a+strconv.Itoa(i), true)
@@ -615,8 +1059,24 @@ func ExampleNewRingMap() {
 	}
 }
 
+func ExampleNewRingMapOf() {
+	m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+
+	m.Set("foo", 1)
+	m.Set("bar", 2)
+	m.Put("foo", 3)
+
+	for _, key := range m.Keys() {
+		value, _ := m.Get(key)
+		fmt.Println(key, value)
+	}
+	// Output:
+	// bar 2
+	// foo 3
+}
+
 func ExampleRingMap_Front() {
-	m := ringmap.NewRingMap(ringMapCapacity)
+	m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 	m.Set(1, true)
 	m.Set(2, true)
 
@@ -646,8 +1106,9 @@ func BenchmarkBigMap_Set(b *testing.B) {
 
 func benchmarkBigRingMap_Set() func(b *testing.B) {
 	return func(b *testing.B) {
+		b.ReportAllocs()
 		for j := 0; j < b.N; j++ {
-			m := ringmap.NewRingMap(ringMapCapacity)
+			m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 			for i := 0; i < 10000000; i++ {
 				m.Set(i, true)
 			}
@@ -679,7 +1140,7 @@ func BenchmarkBigMap_Get(b *testing.B) {
 }
 
 func benchmarkBigRingMap_Get() func(b *testing.B) {
-	m := ringmap.NewRingMap(ringMapCapacity)
+	m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 	for i := 0; i < 10000000; i++ {
 		m.Set(i, true)
 	}
@@ -715,7 +1176,7 @@ func BenchmarkBigMap_Iterate(b *testing.B) {
 }
 
 func benchmarkBigRingMap_Iterate() func(b *testing.B) {
-	m := ringmap.NewRingMap(ringMapCapacity)
+	m := ringmap.NewRingMapOf[int, bool](ringMapCapacity)
 	for i := 0; i < 10000000; i++ {
 		m.Set(i, true)
 	}
@@ -753,7 +1214,7 @@ func BenchmarkBigMapString_Set(b *testing.B) {
 func benchmarkBigRingMapString_Set() func(b *testing.B) {
 	return func(b *testing.B) {
 		for j := 0; j < b.N; j++ {
-			m := ringmap.NewRingMap(ringMapCapacity)
+			m := ringmap.NewRingMapOf[string, bool](ringMapCapacity)
 			a := "1234567"
 			for i := 0; i < 10000000; i++ {
 				m.Set(a+strconv.Itoa(i), true)
@@ -787,7 +1248,7 @@ func BenchmarkBigMapString_Get(b *testing.B) {
 }
 
 func benchmarkBigRingMapString_Get() func(b *testing.B) {
-	m := ringmap.NewRingMap(ringMapCapacity)
+	m := ringmap.NewRingMapOf[string, bool](ringMapCapacity)
 	a := "1234567"
 	for i := 0; i < 10000000; i++ {
 		m.Set(a+strconv.Itoa(i), true)
@@ -825,7 +1286,7 @@ func BenchmarkBigMapString_Iterate(b *testing.B) {
 }
 
 func benchmarkBigRingMapString_Iterate() func(b *testing.B) {
-	m := ringmap.NewRingMap(ringMapCapacity)
+	m := ringmap.NewRingMapOf[string, bool](ringMapCapacity)
 	a := "12345678"
 	for i := 0; i < 10000000; i++ {
 		m.Set(a+strconv.Itoa(i), true)
@@ -857,6 +1318,16 @@ func BenchmarkAll(b *testing.B) {
 	b.Run("BenchmarkRingMap_Iterate", BenchmarkRingMap_Iterate)
 	b.Run("BenchmarkMap_Iterate", BenchmarkMap_Iterate)
 
+	b.Run("BenchmarkSyncRingMap_SetParallel", BenchmarkSyncRingMap_SetParallel)
+	b.Run("BenchmarkShardedRingMap_SetParallel", BenchmarkShardedRingMap_SetParallel)
+	b.Run("BenchmarkSyncRingMap_GetParallel", BenchmarkSyncRingMap_GetParallel)
+	b.Run("BenchmarkShardedRingMap_GetParallel", BenchmarkShardedRingMap_GetParallel)
+	b.Run("BenchmarkShardedRingMap_MixedParallel", BenchmarkShardedRingMap_MixedParallel)
+
+	b.Run("BenchmarkRingMap_Churn", BenchmarkRingMap_Churn)
+	b.Run("BenchmarkLRURingMap_Churn", BenchmarkLRURingMap_Churn)
+	b.Run("BenchmarkRingMapString_Churn", BenchmarkRingMapString_Churn)
+
 	b.Run("BenchmarkBigMap_Set", BenchmarkBigMap_Set)
 	b.Run("BenchmarkBigRingMap_Set", BenchmarkBigRingMap_Set)
 	b.Run("BenchmarkBigMap_Get", BenchmarkBigMap_Get)
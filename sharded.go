@@ -0,0 +1,383 @@
+package ringmap
+
+import (
+	"fmt"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"time"
+)
+
+// ShardedRingMap spreads its keys over a number of SyncRingMap shards, each with
+// its own lock, so that goroutines working on different keys rarely contend.
+//
+// Each shard owns an equal part of the capacity and evicts its own Front
+// element when it's full. Eviction is therefore FIFO per shard but only
+// approximately FIFO across the whole map: a Set can evict an element of its
+// own shard while older elements remain in other shards.
+type ShardedRingMap[K comparable, V any] struct {
+	shards []*SyncRingMap[K, V]
+	hash   func(K) uint64
+}
+
+// NewShardedRingMap creates a new concurrent map with a total maximum size of
+// capacity, split across the given number of shards. Keys are distributed with
+// a hash that supports every comparable key type.
+func NewShardedRingMap[K comparable, V any](capacity, shards int) *ShardedRingMap[K, V] {
+	return NewShardedRingMapWithHasher[K, V](capacity, shards, newDefaultHasher[K]())
+}
+
+// NewShardedRingMapWithHasher is like NewShardedRingMap but distributes keys
+// with hash. Equal keys must have equal hashes.
+//
+// The number of shards is at least one and, for a bounded map, no more than
+// the capacity so that every shard can hold an element. Any remainder of the
+// capacity is given to the first shards.
+func NewShardedRingMapWithHasher[K comparable, V any](capacity, shards int, hash func(K) uint64) *ShardedRingMap[K, V] {
+	if shards < 1 {
+		shards = 1
+	}
+	if capacity > 0 && shards > capacity {
+		shards = capacity
+	}
+
+	m := &ShardedRingMap[K, V]{
+		shards: make([]*SyncRingMap[K, V], shards),
+		hash:   hash,
+	}
+	for i := range m.shards {
+		m.shards[i] = NewSyncRingMap[K, V](shardCapacity(capacity, i, shards))
+	}
+
+	return m
+}
+
+// shardCapacity returns the part of capacity owned by shard i of n. Any
+// remainder is given to the first shards.
+func shardCapacity(capacity, i, n int) int {
+	if capacity <= 0 {
+		return capacity
+	}
+
+	c := capacity / n
+	if i < capacity%n {
+		c++
+	}
+
+	return c
+}
+
+// Resize changes the total capacity of the map, splitting it over the shards
+// like NewShardedRingMap does. The number of shards doesn't change, so shrinking
+// below it leaves some shards with zero capacity, which reject every insert.
+// See RingMapOf.Resize.
+func (m *ShardedRingMap[K, V]) Resize(capacity int) error {
+	if capacity < 0 && capacity != Unbounded {
+		return fmt.Errorf("%w: %d", ErrInvalidCapacity, capacity)
+	}
+
+	for i, shard := range m.shards {
+		if err := shard.Resize(shardCapacity(capacity, i, len(m.shards))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *ShardedRingMap[K, V]) shard(key K) *SyncRingMap[K, V] {
+	return m.shards[m.hash(key)%uint64(len(m.shards))]
+}
+
+// Shards returns the number of shards.
+func (m *ShardedRingMap[K, V]) Shards() int {
+	return len(m.shards)
+}
+
+// OnEvict sets the function that is called for every entry that leaves any of
+// the shards. See RingMapOf.OnEvict. fn may be called by several goroutines at
+// once, one per shard, and must not use m.
+func (m *ShardedRingMap[K, V]) OnEvict(fn EvictFunc[K, V]) {
+	for _, shard := range m.shards {
+		shard.OnEvict(fn)
+	}
+}
+
+// Get returns the value for a key. If the key does not exist, the second return
+// parameter will be false and the value will be the zero value of V.
+func (m *ShardedRingMap[K, V]) Get(key K) (V, bool) {
+	return m.shard(key).Get(key)
+}
+
+// Peek returns the value for a key like Get, but never moves the element.
+func (m *ShardedRingMap[K, V]) Peek(key K) (V, bool) {
+	return m.shard(key).Peek(key)
+}
+
+// GetOrDefault returns the value for a key. If the key does not exist, returns
+// the default value instead.
+func (m *ShardedRingMap[K, V]) GetOrDefault(key K, defaultValue V) V {
+	return m.shard(key).GetOrDefault(key, defaultValue)
+}
+
+// Set will set (or replace) a value for a key. If a new key is being added and
+// its shard is full, the front element of that shard is deleted. See
+// RingMapOf.Set.
+func (m *ShardedRingMap[K, V]) Set(key K, value V) bool {
+	return m.shard(key).Set(key, value)
+}
+
+// Put will set a value for a key, moving it to the back of its shard. See
+// RingMapOf.Put.
+func (m *ShardedRingMap[K, V]) Put(key K, value V) bool {
+	return m.shard(key).Put(key, value)
+}
+
+// TrySet is like Set, but returns an error if the key could not be added. See
+// RingMapOf.TrySet.
+func (m *ShardedRingMap[K, V]) TrySet(key K, value V) (bool, error) {
+	return m.shard(key).TrySet(key, value)
+}
+
+// TryPut is like Put, but returns an error if the key could not be added. See
+// RingMapOf.TryPut.
+func (m *ShardedRingMap[K, V]) TryPut(key K, value V) (bool, error) {
+	return m.shard(key).TryPut(key, value)
+}
+
+// SetWithTTL is like Set, but the entry expires after ttl. See
+// RingMapOf.SetWithTTL.
+func (m *ShardedRingMap[K, V]) SetWithTTL(key K, value V, ttl time.Duration) bool {
+	return m.shard(key).SetWithTTL(key, value, ttl)
+}
+
+// PutWithTTL is like Put, but the entry expires after ttl. See
+// RingMapOf.PutWithTTL.
+func (m *ShardedRingMap[K, V]) PutWithTTL(key K, value V, ttl time.Duration) bool {
+	return m.shard(key).PutWithTTL(key, value, ttl)
+}
+
+// SetDefaultTTL sets the time to live that Set and Put give to entries in every
+// shard. See RingMapOf.SetDefaultTTL.
+func (m *ShardedRingMap[K, V]) SetDefaultTTL(ttl time.Duration) {
+	for _, shard := range m.shards {
+		shard.SetDefaultTTL(ttl)
+	}
+}
+
+// SetClock sets the clock every shard uses to expire entries. The clock must be
+// safe for concurrent use.
+func (m *ShardedRingMap[K, V]) SetClock(clock Clock) {
+	for _, shard := range m.shards {
+		shard.SetClock(clock)
+	}
+}
+
+// PurgeExpired removes every expired entry from every shard and returns how
+// many were removed.
+func (m *ShardedRingMap[K, V]) PurgeExpired() (n int) {
+	for _, shard := range m.shards {
+		n += shard.PurgeExpired()
+	}
+
+	return n
+}
+
+// Delete will remove a key from the map. It will return true if the key was
+// removed (the key did exist).
+func (m *ShardedRingMap[K, V]) Delete(key K) bool {
+	return m.shard(key).Delete(key)
+}
+
+// Len returns the number of elements in all shards. Shards are counted one at a
+// time, so the result is not a snapshot while other goroutines write.
+func (m *ShardedRingMap[K, V]) Len() (n int) {
+	for _, shard := range m.shards {
+		n += shard.Len()
+	}
+
+	return n
+}
+
+// Stats returns the sum of the counters of all shards. PeakLen is the sum of
+// the peak lengths of the shards, which may have peaked at different times, so
+// it is an upper bound of the peak length of the map.
+func (m *ShardedRingMap[K, V]) Stats() Stats {
+	total := Stats{Evictions: make(map[EvictReason]uint64)}
+	for _, shard := range m.shards {
+		s := shard.Stats()
+		total.Hits += s.Hits
+		total.Misses += s.Misses
+		total.Inserts += s.Inserts
+		total.Updates += s.Updates
+		for reason, n := range s.Evictions {
+			total.Evictions[reason] += n
+		}
+		total.PeakLen += s.PeakLen
+	}
+
+	return total
+}
+
+// ResetStats sets the counters of all shards to zero.
+func (m *ShardedRingMap[K, V]) ResetStats() {
+	for _, shard := range m.shards {
+		shard.ResetStats()
+	}
+}
+
+// Capacity returns the sum of the capacities of all shards, which is the
+// capacity the map was created with, or a negative number if it is unbounded.
+func (m *ShardedRingMap[K, V]) Capacity() (n int) {
+	for _, shard := range m.shards {
+		c := shard.Capacity()
+		if c < 0 {
+			return c
+		}
+		n += c
+	}
+
+	return n
+}
+
+// Keys returns the keys of all shards. The keys of each shard are in order from
+// Front to Back, but there is no order between the keys of different shards.
+func (m *ShardedRingMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.Len())
+	for _, shard := range m.shards {
+		keys = append(keys, shard.Keys()...)
+	}
+
+	return keys
+}
+
+// Range calls f for each key and value, one shard at a time, holding the read
+// lock of the shard being visited. If f returns false, Range stops the
+// iteration.
+func (m *ShardedRingMap[K, V]) Range(f func(key K, value V) bool) {
+	more := true
+	for _, shard := range m.shards {
+		shard.Range(func(key K, value V) bool {
+			more = f(key, value)
+			return more
+		})
+		if !more {
+			return
+		}
+	}
+}
+
+// newDefaultHasher returns a hash function for any comparable type. Common key
+// types are hashed directly. Anything else is hashed with reflection the way ==
+// compares it: pointers and channels by address, arrays and structs field by
+// field, and interfaces by their dynamic type and value.
+func newDefaultHasher[K comparable]() func(K) uint64 {
+	seed := maphash.MakeSeed()
+
+	return func(key K) uint64 {
+		switch k := interface{}(key).(type) {
+		case string:
+			return maphash.String(seed, k)
+		case int:
+			return mix64(uint64(k))
+		case int8:
+			return mix64(uint64(k))
+		case int16:
+			return mix64(uint64(k))
+		case int32:
+			return mix64(uint64(k))
+		case int64:
+			return mix64(uint64(k))
+		case uint:
+			return mix64(uint64(k))
+		case uint8:
+			return mix64(uint64(k))
+		case uint16:
+			return mix64(uint64(k))
+		case uint32:
+			return mix64(uint64(k))
+		case uint64:
+			return mix64(k)
+		case uintptr:
+			return mix64(uint64(k))
+		case float64:
+			return hashFloat(k)
+		case float32:
+			return hashFloat(float64(k))
+		case bool:
+			if k {
+				return 1
+			}
+			return 0
+		default:
+			return hashValue(seed, reflect.ValueOf(k))
+		}
+	}
+}
+
+// hashValue hashes a comparable value so that values that are == hash the
+// same.
+func hashValue(seed maphash.Seed, v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.String:
+		return maphash.String(seed, v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return mix64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return mix64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return hashFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return mix64(hashFloat(real(c)) ^ hashFloat(imag(c))*31)
+	case reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		return mix64(uint64(v.Pointer()))
+	case reflect.Array:
+		var h uint64
+		for i := 0; i < v.Len(); i++ {
+			h = mix64(h ^ hashValue(seed, v.Index(i)))
+		}
+		return h
+	case reflect.Struct:
+		var h uint64
+		for i := 0; i < v.NumField(); i++ {
+			h = mix64(h ^ hashValue(seed, v.Field(i)))
+		}
+		return h
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		v = v.Elem()
+		return mix64(maphash.String(seed, v.Type().String()) ^ hashValue(seed, v))
+	default:
+		// Comparable keys have no other kinds.
+		return 0
+	}
+}
+
+// hashFloat hashes a float so that 0 and -0, which are equal, hash the same.
+func hashFloat(f float64) uint64 {
+	if f == 0 {
+		return 0
+	}
+
+	return mix64(math.Float64bits(f))
+}
+
+// mix64 is the splitmix64 finalizer. It spreads sequential integers over all
+// the bits so that they are distributed evenly over the shards.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return x
+}
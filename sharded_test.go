@@ -0,0 +1,171 @@
+package ringmap_test
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedRingMap(t *testing.T) {
+	t.Run("SplitsCapacity", func(t *testing.T) {
+		m := ringmap.NewShardedRingMap[int, bool](10, 4)
+		assert.Equal(t, 4, m.Shards())
+		assert.Equal(t, 10, m.Capacity())
+	})
+
+	t.Run("NoMoreShardsThanCapacity", func(t *testing.T) {
+		m := ringmap.NewShardedRingMap[int, bool](3, 8)
+		assert.Equal(t, 3, m.Shards())
+		assert.Equal(t, 3, m.Capacity())
+	})
+
+	t.Run("AtLeastOneShard", func(t *testing.T) {
+		m := ringmap.NewShardedRingMap[int, bool](3, 0)
+		assert.Equal(t, 1, m.Shards())
+	})
+
+	t.Run("GetSetDelete", func(t *testing.T) {
+		m := ringmap.NewShardedRingMap[string, int](ringMapCapacity, 8)
+		assert.True(t, m.Set("foo", 1))
+		assert.False(t, m.Set("foo", 2))
+		assert.True(t, m.Put("bar", 3))
+
+		value, ok := m.Get("foo")
+		assert.True(t, ok)
+		assert.Equal(t, 2, value)
+		assert.Equal(t, 3, m.GetOrDefault("bar", 0))
+		assert.Equal(t, 2, m.Len())
+
+		assert.True(t, m.Delete("foo"))
+		_, ok = m.Get("foo")
+		assert.False(t, ok)
+	})
+
+	t.Run("NeverExceedsCapacity", func(t *testing.T) {
+		m := ringmap.NewShardedRingMap[int, bool](100, 8)
+		for i := 0; i < 10000; i++ {
+			m.Set(i, true)
+		}
+		assert.True(t, m.Len() <= m.Capacity())
+		assert.True(t, m.Len() > 0)
+	})
+
+	t.Run("KeysCoverAllShards", func(t *testing.T) {
+		m := ringmap.NewShardedRingMap[int, bool](ringMapCapacity, 8)
+		for i := 0; i < 100; i++ {
+			m.Set(i, true)
+		}
+		keys := m.Keys()
+		sort.Ints(keys)
+		assert.Len(t, keys, 100)
+		assert.Equal(t, 0, keys[0])
+		assert.Equal(t, 99, keys[99])
+	})
+
+	t.Run("RangeStops", func(t *testing.T) {
+		m := ringmap.NewShardedRingMap[int, bool](ringMapCapacity, 8)
+		for i := 0; i < 100; i++ {
+			m.Set(i, true)
+		}
+		visited := 0
+		m.Range(func(int, bool) bool {
+			visited++
+			return visited < 10
+		})
+		assert.Equal(t, 10, visited)
+	})
+
+	t.Run("DefaultHasherIsConsistent", func(t *testing.T) {
+		type point struct{ x, y int }
+		m := ringmap.NewShardedRingMap[point, string](ringMapCapacity, 16)
+		m.Set(point{1, 2}, "a")
+		assert.Equal(t, "a", m.GetOrDefault(point{1, 2}, ""))
+
+		floats := ringmap.NewShardedRingMap[float64, string](ringMapCapacity, 16)
+		floats.Set(0.0, "zero")
+		negativeZero := -1 * 0.0
+		assert.Equal(t, "zero", floats.GetOrDefault(negativeZero, ""))
+
+		untyped := ringmap.NewShardedRingMap[interface{}, string](ringMapCapacity, 16)
+		untyped.Set("foo", "a")
+		untyped.Set(123, "b")
+		assert.Equal(t, "a", untyped.GetOrDefault("foo", ""))
+		assert.Equal(t, "b", untyped.GetOrDefault(123, ""))
+	})
+
+	t.Run("DefaultHasherPointerKeys", func(t *testing.T) {
+		type node struct{ value int }
+		m := ringmap.NewShardedRingMap[*node, string](ringMapCapacity, 16)
+		keys := make([]*node, 100)
+		for i := range keys {
+			keys[i] = &node{i}
+			m.Set(keys[i], strconv.Itoa(i))
+		}
+
+		// Changing what a key points to doesn't change the key.
+		for i, key := range keys {
+			key.value = -1
+			assert.Equal(t, strconv.Itoa(i), m.GetOrDefault(key, ""))
+		}
+		assert.Equal(t, "", m.GetOrDefault(&node{0}, ""))
+	})
+
+	t.Run("DefaultHasherFloatStructKeys", func(t *testing.T) {
+		type point struct {
+			x float64
+			p *int
+		}
+		one := 1
+		negativeZero := math.Copysign(0, -1)
+
+		// 0 and -0 are equal, so they are the same key in every shard
+		// layout.
+		for i := 0; i < 20; i++ {
+			m := ringmap.NewShardedRingMap[point, string](ringMapCapacity, 64)
+			m.Set(point{x: 0, p: &one}, "a")
+			m.Set(point{x: negativeZero, p: &one}, "b")
+			assert.Equal(t, 1, m.Len())
+			assert.Equal(t, "b", m.GetOrDefault(point{x: 0, p: &one}, ""))
+		}
+	})
+
+	t.Run("CustomHasher", func(t *testing.T) {
+		m := ringmap.NewShardedRingMapWithHasher[int, bool](4, 2, func(key int) uint64 {
+			return uint64(key)
+		})
+		// Even keys all go to the first shard, which holds two elements.
+		m.Set(0, true)
+		m.Set(2, true)
+		m.Set(4, true)
+		m.Set(1, true)
+		assert.Equal(t, 3, m.Len())
+		_, ok := m.Get(0)
+		assert.False(t, ok)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		m := ringmap.NewShardedRingMap[string, int](1000, 16)
+		var wg sync.WaitGroup
+		for g := 0; g < 16; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					key := strconv.Itoa(g*1000 + i)
+					m.Set(key, i)
+					m.Get(key)
+					if i%3 == 0 {
+						m.Delete(key)
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+		assert.True(t, m.Len() <= m.Capacity())
+	})
+}
@@ -0,0 +1,93 @@
+package ringmap
+
+// SievePolicy is the SIEVE policy of Zhang, Yang, Yue, Vigfusson and Rashmi.
+// A read only sets a visited bit on its key, so the map stays in insertion
+// order and SyncRingMap serves Get and GetOrDefault under its read lock.
+//
+// To find a victim, a hand sweeps from older to newer keys, clearing the
+// visited bits it passes, and stops at the first key that wasn't visited.
+// The next sweep starts where the last one stopped, and the hand wraps from
+// Back() to Front(). Unlike CLOCK, visited keys stay where they are instead of
+// moving to the back, so the hand gets to the new keys before it wraps around
+// to the old keys it kept, and new keys that aren't used again leave quickly.
+// If the key under the hand is deleted, the next sweep starts at Front().
+type SievePolicy[K comparable] struct {
+	o       Ordering[K]
+	visited visitedBits[K]
+	hand    K
+	hasHand bool
+}
+
+// NewSievePolicy creates a new SIEVE eviction policy.
+func NewSievePolicy[K comparable]() *SievePolicy[K] {
+	return &SievePolicy[K]{visited: newVisitedBits[K]()}
+}
+
+// NewSieveRingMapOf creates a new ordered map in SIEVE mode with a maximum size
+// that holds keys of type K and values of type V.
+func NewSieveRingMapOf[K comparable, V any](capacity int) *RingMapOf[K, V] {
+	return NewRingMapWithPolicy[K, V](capacity, NewSievePolicy[K]())
+}
+
+// Attach implements EvictionPolicy.
+func (p *SievePolicy[K]) Attach(o Ordering[K]) {
+	p.o = o
+}
+
+// OnInsert implements EvictionPolicy. New keys aren't visited.
+func (p *SievePolicy[K]) OnInsert(key K) {
+	p.visited.add(key)
+}
+
+// OnAccess implements EvictionPolicy. It marks key as visited.
+func (p *SievePolicy[K]) OnAccess(key K) {
+	p.visited.set(key)
+}
+
+// OnUpdate implements EvictionPolicy. It marks key as visited.
+func (p *SievePolicy[K]) OnUpdate(key K) {
+	p.visited.set(key)
+}
+
+// OnDelete implements EvictionPolicy.
+func (p *SievePolicy[K]) OnDelete(key K) {
+	p.visited.remove(key)
+	if p.hasHand && p.hand == key {
+		p.hasHand = false
+	}
+}
+
+// Victim implements EvictionPolicy. It moves the hand to the first key that
+// wasn't visited, and leaves it on the key after it.
+func (p *SievePolicy[K]) Victim() (K, bool) {
+	key, ok := p.hand, p.hasHand
+	if !ok {
+		key, ok = p.o.Front()
+	}
+	if !ok {
+		return key, false
+	}
+
+	for p.visited.clear(key) {
+		key = p.next(key)
+	}
+	p.hand, p.hasHand = p.o.Next(key)
+
+	return key, true
+}
+
+// next returns the key after key, wrapping from the back to the front.
+func (p *SievePolicy[K]) next(key K) K {
+	if next, ok := p.o.Next(key); ok {
+		return next
+	}
+
+	front, _ := p.o.Front()
+	return front
+}
+
+// ConcurrentAccess implements ConcurrentAccessPolicy. Accesses only set a
+// visited bit atomically.
+func (p *SievePolicy[K]) ConcurrentAccess() bool {
+	return true
+}
@@ -0,0 +1,106 @@
+package ringmap_test
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSievePolicy(t *testing.T) {
+	t.Run("KeepsVisitedInPlace", func(t *testing.T) {
+		m := ringmap.NewSieveRingMapOf[string, int](3)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		m.Get("a")
+		m.Set("d", 4)
+		assert.Equal(t, []string{"a", "c", "d"}, m.Keys())
+
+		// The hand carries on from c, and a keeps its place.
+		m.Set("e", 5)
+		assert.Equal(t, []string{"a", "d", "e"}, m.Keys())
+		m.Set("f", 6)
+		assert.Equal(t, []string{"a", "e", "f"}, m.Keys())
+	})
+
+	t.Run("HandWraps", func(t *testing.T) {
+		m := ringmap.NewSieveRingMapOf[string, int](3)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		m.Set("d", 4) // the hand stops at a and moves on to b
+		m.Get("b")
+		m.Get("c")
+		m.Get("d")
+
+		// Every key was visited, so the hand goes around once, clearing
+		// them, and stops at b.
+		m.Set("e", 5)
+		assert.Equal(t, []string{"c", "d", "e"}, m.Keys())
+	})
+
+	t.Run("DeletedHand", func(t *testing.T) {
+		m := ringmap.NewSieveRingMapOf[string, int](3)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		m.Set("d", 4) // the hand moves on to b
+		m.Delete("b")
+		m.Set("e", 5)
+		m.Get("c")
+
+		// The hand starts over at the front.
+		m.Set("f", 6)
+		assert.Equal(t, []string{"c", "e", "f"}, m.Keys())
+	})
+
+	t.Run("UpdateVisits", func(t *testing.T) {
+		m := ringmap.NewSieveRingMapOf[string, int](2)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("a", 3)
+		m.Set("c", 4)
+		assert.Equal(t, []string{"a", "c"}, m.Keys())
+	})
+
+	t.Run("ConcurrentAccess", func(t *testing.T) {
+		var p interface{} = ringmap.NewSievePolicy[int]()
+		c, ok := p.(ringmap.ConcurrentAccessPolicy)
+		assert.True(t, ok)
+		assert.True(t, c.ConcurrentAccess())
+	})
+}
+
+func TestSievePolicy_Trace(t *testing.T) {
+	trace := hotsetTrace(rand.New(rand.NewSource(1)), 20000, 50, "")
+
+	fifo := replay(ringmap.NewRingMapOf[string, int](100), trace)
+	sieve := replay(ringmap.NewSieveRingMapOf[string, int](100), trace)
+	assert.Greater(t, sieve, fifo+0.1)
+}
+
+func TestSyncRingMap_Sieve(t *testing.T) {
+	s := ringmap.Synchronize(ringmap.NewSieveRingMapOf[string, int](100))
+
+	// Reads share the read lock and only race on the visited bits, which the
+	// race detector checks.
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := strconv.Itoa((g + i) % 150)
+				if _, ok := s.Get(key); !ok {
+					s.Set(key, i)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 100, s.Len())
+}
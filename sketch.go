@@ -0,0 +1,112 @@
+package ringmap
+
+// sketchDepth is the number of rows of a sketch, each indexed by its own hash.
+const sketchDepth = 4
+
+// sketchMax is the largest count a counter of a sketch holds.
+const sketchMax = 15
+
+// sketch estimates how often keys were seen, in memory that doesn't depend on
+// the number of keys. A count-min sketch counts every key in one counter of
+// each of its rows and takes the smallest as the estimate, which may be too
+// high through collisions but is never too low. In front of it, a doorkeeper
+// bloom filter takes the first sighting of each key, so the many keys that
+// are only seen once don't take up the counters.
+//
+// Once there were ten times as many sightings as counters in a row, all
+// counts are halved and the doorkeeper is cleared, so the estimates follow
+// recent history.
+type sketch struct {
+	counters []uint8
+	mask     uint64 // the width of a row minus one
+	door     []uint64
+	doorMask uint64
+	seen     int
+	sample   int
+}
+
+// newSketch creates a sketch for about n keys.
+func newSketch(n int) *sketch {
+	width := 16
+	for width < n {
+		width *= 2
+	}
+
+	return &sketch{
+		counters: make([]uint8, sketchDepth*width),
+		mask:     uint64(width - 1),
+		door:     make([]uint64, width/8),
+		doorMask: uint64(width*8 - 1),
+		sample:   10 * width,
+	}
+}
+
+// add counts a sighting of the key with hash h.
+func (s *sketch) add(h uint64) {
+	if !s.admitted(h) {
+		s.admit(h)
+	} else {
+		for i := 0; i < sketchDepth; i++ {
+			if c := &s.counters[s.index(h, i)]; *c < sketchMax {
+				*c++
+			}
+		}
+	}
+
+	s.seen++
+	if s.seen >= s.sample {
+		s.reset()
+	}
+}
+
+// estimate returns how often the key with hash h was seen, at most sketchMax
+// plus one.
+func (s *sketch) estimate(h uint64) int {
+	count := uint8(sketchMax)
+	for i := 0; i < sketchDepth; i++ {
+		if c := s.counters[s.index(h, i)]; c < count {
+			count = c
+		}
+	}
+
+	if s.admitted(h) {
+		return int(count) + 1
+	}
+	return int(count)
+}
+
+// index returns the index of the counter for hash h in row i.
+func (s *sketch) index(h uint64, i int) int {
+	return i*int(s.mask+1) + int(mix64(h+uint64(i)*0x9e3779b97f4a7c15)&s.mask)
+}
+
+// admitted returns true if the doorkeeper has seen the key with hash h.
+func (s *sketch) admitted(h uint64) bool {
+	for i := uint64(0); i < 3; i++ {
+		bit := (h + i*(h>>32|1)) & s.doorMask
+		if s.door[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// admit adds the key with hash h to the doorkeeper.
+func (s *sketch) admit(h uint64) {
+	for i := uint64(0); i < 3; i++ {
+		bit := (h + i*(h>>32|1)) & s.doorMask
+		s.door[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// reset halves every count and clears the doorkeeper.
+func (s *sketch) reset() {
+	for i := range s.counters {
+		s.counters[i] /= 2
+	}
+	for i := range s.door {
+		s.door[i] = 0
+	}
+	s.seen /= 2
+}
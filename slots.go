@@ -0,0 +1,251 @@
+package ringmap
+
+import (
+	"sort"
+	"time"
+)
+
+// none is the index of a missing slot.
+const none = -1
+
+// slot holds one entry of a RingMap. Slots are linked into a doubly linked list
+// by index, from the front to the back of the map; free slots are linked
+// through next.
+type slot[K comparable, V any] struct {
+	key   K
+	value V
+
+	// When the entry expires, or the zero time if it never does.
+	expires time.Time
+
+	// The weight of the entry towards the map's MaxWeight().
+	weight int64
+
+	prev, next int
+
+	// gen is incremented whenever the slot is freed, so a handle that
+	// remembers it can tell whether the slot still holds the same entry.
+	gen uint32
+}
+
+// slots is the storage of a RingMap: chunks of slots and a list of the used
+// ones in order. Each chunk is allocated once and never copied: a bounded map
+// gets its capacity in the first chunk, and growing adds another chunk. Freed
+// slots are reused, so a map that stays full doesn't allocate.
+type slots[K comparable, V any] struct {
+	chunks      [][]slot[K, V]
+	starts      []int // the index of the first slot of each chunk
+	size        int   // the number of slots in all chunks
+	used        int   // the number of slots that were ever used
+	front, back int
+	free        int
+	cursors     []*cursor // the walks in progress
+}
+
+// cursor is the position of a walk over the list: the index of the slot it
+// visits next, and of the last slot it visits. Both are moved on when their
+// slot is unlinked, so a walk survives any change to the list, and ends even
+// if it keeps moving entries past its end.
+type cursor struct {
+	next, last int
+	forward    bool
+}
+
+// newSlots creates the storage for a map with the given capacity. Bounded maps
+// get exactly capacity slots up front; unbounded ones grow as needed.
+func newSlots[K comparable, V any](capacity int) slots[K, V] {
+	l := slots[K, V]{chunks: [][]slot[K, V]{nil}, starts: []int{0}, front: none, back: none, free: none}
+	l.grow(capacity)
+
+	return l
+}
+
+// grow makes room for at least capacity slots without allocating again. The
+// slots that are added go into a new chunk, so the existing ones stay where
+// they are.
+func (l *slots[K, V]) grow(capacity int) {
+	if capacity <= l.size {
+		return
+	}
+
+	chunk := make([]slot[K, V], capacity-l.size)
+	if l.size == 0 {
+		l.chunks[0] = chunk
+	} else {
+		l.chunks = append(l.chunks, chunk)
+		l.starts = append(l.starts, l.size)
+	}
+	l.size = capacity
+}
+
+// at returns the slot at index i.
+func (l *slots[K, V]) at(i int) *slot[K, V] {
+	if first := l.chunks[0]; i < len(first) {
+		return &first[i]
+	}
+
+	return l.far(i)
+}
+
+// far returns the slot at index i, which is past the first chunk.
+func (l *slots[K, V]) far(i int) *slot[K, V] {
+	k := sort.SearchInts(l.starts, i+1) - 1
+	return &l.chunks[k][i-l.starts[k]]
+}
+
+// live returns true if the slot at index i still holds the entry it held in
+// generation gen.
+func (l *slots[K, V]) live(i int, gen uint32) bool {
+	return i >= 0 && i < l.used && l.at(i).gen == gen
+}
+
+// pushBack stores a new entry at the back of the list and returns its index.
+func (l *slots[K, V]) pushBack(key K, value V) int {
+	i := l.free
+	if i == none {
+		if l.used == l.size {
+			// Only unbounded maps run out of slots.
+			l.grow(2*l.size + 8)
+		}
+		i = l.used
+		l.used++
+	} else {
+		l.free = l.at(i).next
+	}
+
+	// New entries are visited by the forward walks that haven't ended.
+	for _, c := range l.cursors {
+		if c.forward && c.next != none && c.last == l.back {
+			c.last = i
+		}
+	}
+
+	s := l.at(i)
+	s.key = key
+	s.value = value
+	l.linkBack(i)
+
+	return i
+}
+
+// remove unlinks the slot at index i and frees it.
+func (l *slots[K, V]) remove(i int) {
+	l.unlink(i)
+	s := l.at(i)
+	*s = slot[K, V]{prev: none, next: l.free, gen: s.gen + 1}
+	l.free = i
+}
+
+// moveToFront moves the slot at index i to the front of the list.
+func (l *slots[K, V]) moveToFront(i int) {
+	if l.front == i {
+		return
+	}
+
+	l.unlink(i)
+	l.at(i).next = l.front
+	l.at(l.front).prev = i
+	l.front = i
+}
+
+// moveToBack moves the slot at index i to the back of the list.
+func (l *slots[K, V]) moveToBack(i int) {
+	if l.back == i {
+		return
+	}
+
+	l.unlink(i)
+	l.linkBack(i)
+}
+
+// moveBefore moves the slot at index i in front of the slot at index mark.
+func (l *slots[K, V]) moveBefore(i, mark int) {
+	if i == mark || l.at(mark).prev == i {
+		return
+	}
+
+	l.unlink(i)
+	prev := l.at(mark).prev
+	l.at(i).prev = prev
+	l.at(i).next = mark
+	l.at(mark).prev = i
+	if prev == none {
+		l.front = i
+	} else {
+		l.at(prev).next = i
+	}
+}
+
+// step returns the index of the slot after the slot at index i, towards the
+// back if forward is true and towards the front otherwise.
+func (l *slots[K, V]) step(i int, forward bool) int {
+	if forward {
+		return l.at(i).next
+	}
+
+	return l.at(i).prev
+}
+
+// track starts a walk from the front towards the back if forward is true, and
+// from the back towards the front otherwise.
+func (l *slots[K, V]) track(forward bool) *cursor {
+	c := &cursor{next: l.front, last: l.back, forward: true}
+	if !forward {
+		c = &cursor{next: l.back, last: l.front}
+	}
+	l.cursors = append(l.cursors, c)
+
+	return c
+}
+
+// untrack ends the walk of c.
+func (l *slots[K, V]) untrack(c *cursor) {
+	last := len(l.cursors) - 1
+	for j := last; j >= 0; j-- {
+		if l.cursors[j] == c {
+			copy(l.cursors[j:], l.cursors[j+1:])
+			l.cursors[last] = nil
+			l.cursors = l.cursors[:last]
+			return
+		}
+	}
+}
+
+func (l *slots[K, V]) linkBack(i int) {
+	s := l.at(i)
+	s.prev = l.back
+	s.next = none
+	if l.back == none {
+		l.front = i
+	} else {
+		l.at(l.back).next = i
+	}
+	l.back = i
+}
+
+func (l *slots[K, V]) unlink(i int) {
+	for _, c := range l.cursors {
+		switch {
+		case c.next == i && c.last == i:
+			c.next, c.last = none, none
+		case c.next == i:
+			c.next = l.step(i, c.forward)
+		case c.last == i:
+			c.last = l.step(i, !c.forward)
+		}
+	}
+
+	s := l.at(i)
+	if s.prev == none {
+		l.front = s.next
+	} else {
+		l.at(s.prev).next = s.next
+	}
+	if s.next == none {
+		l.back = s.prev
+	} else {
+		l.at(s.next).prev = s.prev
+	}
+	s.prev = none
+	s.next = none
+}
@@ -0,0 +1,159 @@
+package ringmap_test
+
+import (
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlots(t *testing.T) {
+	t.Run("SteadyStateDoesntAllocate", func(t *testing.T) {
+		for name, m := range map[string]*ringmap.RingMapOf[int, int]{
+			"FIFO": ringmap.NewRingMapOf[int, int](ringMapCapacity),
+			"LRU":  ringmap.NewLRURingMapOf[int, int](ringMapCapacity),
+		} {
+			i := 0
+			for ; i < 2*ringMapCapacity; i++ {
+				m.Set(i, i)
+			}
+
+			assert.Zero(t, testing.AllocsPerRun(1000, func() {
+				m.Set(i, i)
+				i++
+			}), name+" Set")
+			assert.Zero(t, testing.AllocsPerRun(1000, func() {
+				m.Put(i, i)
+				i++
+			}), name+" Put")
+			assert.Zero(t, testing.AllocsPerRun(1000, func() {
+				m.Get(i - ringMapCapacity/2)
+			}), name+" Get")
+			assert.Zero(t, testing.AllocsPerRun(1000, func() {
+				m.Delete(i - 1)
+				m.Set(i, i)
+				i++
+			}), name+" Delete")
+		}
+	})
+
+	t.Run("ReusesSlots", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, int](3)
+		for i := 0; i < 100; i++ {
+			m.Set(i, i)
+			if i%7 == 0 {
+				m.Delete(i - 1)
+			}
+		}
+		assert.Equal(t, []int{96, 98, 99}, m.Keys())
+
+		var keys []int
+		for e := m.BackEntry(); e.IsValid(); e = e.Prev() {
+			keys = append(keys, e.Key())
+		}
+		assert.Equal(t, []int{99, 98, 96}, keys)
+	})
+
+	t.Run("StaleEntryAfterReuse", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](1)
+		m.Set("a", 1)
+		e := m.FrontEntry()
+		m.Set("b", 2) // reuses the slot of "a"
+
+		assert.Equal(t, "a", e.Key())
+		assert.Equal(t, 1, e.Value())
+		assert.False(t, e.Next().IsValid())
+		assert.False(t, e.Delete())
+		assert.False(t, e.SetValue(3))
+		assert.Equal(t, []string{"b"}, m.Keys())
+	})
+
+	t.Run("ResizeKeepsOrder", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, int](3)
+		m.Set(1, 1)
+		m.Set(2, 2)
+		m.Set(3, 3)
+		m.Delete(1)
+
+		assert.NoError(t, m.Resize(5))
+		for i := 4; i <= 7; i++ {
+			m.Set(i, i)
+		}
+		assert.Equal(t, []int{3, 4, 5, 6, 7}, m.Keys())
+
+		assert.NoError(t, m.Resize(2))
+		m.Set(8, 8)
+		assert.Equal(t, []int{7, 8}, m.Keys())
+	})
+
+	t.Run("GrowRepeatedly", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, int](2)
+		next := 0
+		for capacity := 2; capacity <= 32; capacity *= 2 {
+			assert.NoError(t, m.Resize(capacity))
+			for i := 0; i < capacity+1; i++ {
+				m.Set(next, next)
+				next++
+			}
+			m.Delete(next - capacity)
+		}
+
+		var keys []int
+		for i := next - 31; i < next; i++ {
+			keys = append(keys, i)
+		}
+		assert.Equal(t, keys, m.Keys())
+	})
+
+	t.Run("Unbounded", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, int](ringmap.Unbounded)
+		for i := 0; i < 1000; i++ {
+			m.Set(i, i)
+		}
+		for i := 0; i < 1000; i += 2 {
+			m.Delete(i)
+		}
+		for i := 1000; i < 1500; i++ {
+			m.Set(i, i)
+		}
+		assert.Equal(t, 1000, m.Len())
+		assert.Equal(t, 1, m.FrontEntry().Key())
+		assert.Equal(t, 1499, m.BackEntry().Key())
+	})
+}
+
+func benchmarkRingMap_Churn(m *ringmap.RingMapOf[int, bool]) func(b *testing.B) {
+	return func(b *testing.B) {
+		for i := 0; i < ringMapCapacity; i++ {
+			m.Set(-i-1, true)
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.Set(i, true)
+		}
+	}
+}
+
+func BenchmarkRingMap_Churn(b *testing.B) {
+	benchmarkRingMap_Churn(ringmap.NewRingMapOf[int, bool](ringMapCapacity))(b)
+}
+
+func BenchmarkLRURingMap_Churn(b *testing.B) {
+	benchmarkRingMap_Churn(ringmap.NewLRURingMapOf[int, bool](ringMapCapacity))(b)
+}
+
+func BenchmarkRingMapString_Churn(b *testing.B) {
+	keys := make([]string, 10*ringMapCapacity)
+	for i := range keys {
+		keys[i] = "key" + string(rune('a'+i%26)) + string(rune(i))
+	}
+	m := ringmap.NewRingMapOf[string, bool](ringMapCapacity)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Set(keys[i%len(keys)], true)
+	}
+}
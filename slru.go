@@ -0,0 +1,127 @@
+package ringmap
+
+import "math"
+
+// DefaultSLRUProbation is the share of the capacity that an SLRUPolicy keeps
+// for its probation segment, unless NewSLRUPolicy is given another one.
+const DefaultSLRUProbation = 0.2
+
+// SLRUPolicy is a segmented LRU policy, which resists scans with less
+// machinery than ARC. New keys start in the probation segment, and a key that
+// is used again, by a Get, GetOrDefault or Set, is promoted to the protected
+// segment. Once the protected segment is full, its least recently used key is
+// demoted to the most recently used end of probation, where it gets another
+// chance to be used before it's evicted. Victims come from the least recently
+// used end of probation, or of the protected segment if probation is empty.
+//
+// The protected segment holds up to the part of the capacity that isn't kept
+// for probation. Probation is only bounded by the capacity, so it takes up any
+// room the protected segment doesn't use.
+//
+// The policy keeps the map in segment order: the probation segment from least
+// to most recently used, followed by the protected segment in the same order.
+// Front() is therefore always the next victim.
+type SLRUPolicy[K comparable] struct {
+	o         Ordering[K]
+	probation float64
+	entries   map[K]*keyEntry[K] // the keys in the protected segment
+	protected keyList[K]
+}
+
+// NewSLRUPolicy creates a new SLRU eviction policy that keeps the share
+// probation of the capacity for the probation segment, for example 0.2 for 20
+// percent. A share outside of (0, 1) is replaced with DefaultSLRUProbation.
+func NewSLRUPolicy[K comparable](probation float64) *SLRUPolicy[K] {
+	if !(probation > 0 && probation < 1) {
+		probation = DefaultSLRUProbation
+	}
+
+	return &SLRUPolicy[K]{
+		probation: probation,
+		entries:   make(map[K]*keyEntry[K]),
+	}
+}
+
+// NewSLRURingMapOf creates a new ordered map in SLRU mode with a maximum size
+// that holds keys of type K and values of type V, keeping the share probation
+// of the capacity for the probation segment. See NewSLRUPolicy.
+func NewSLRURingMapOf[K comparable, V any](capacity int, probation float64) *RingMapOf[K, V] {
+	return NewRingMapWithPolicy[K, V](capacity, NewSLRUPolicy[K](probation))
+}
+
+// ProtectedLen returns the number of keys in the protected segment.
+func (p *SLRUPolicy[K]) ProtectedLen() int {
+	return p.protected.len
+}
+
+// Attach implements EvictionPolicy.
+func (p *SLRUPolicy[K]) Attach(o Ordering[K]) {
+	p.o = o
+}
+
+// OnInsert implements EvictionPolicy. It moves key to the most recently used
+// end of probation, in front of the protected segment.
+func (p *SLRUPolicy[K]) OnInsert(key K) {
+	if p.protected.head != nil {
+		p.o.MoveBefore(key, p.protected.head.key)
+	}
+}
+
+// OnAccess implements EvictionPolicy. It moves key to the most recently used
+// end of the protected segment.
+func (p *SLRUPolicy[K]) OnAccess(key K) {
+	p.promote(key)
+}
+
+// OnUpdate implements EvictionPolicy. It moves key to the most recently used
+// end of the protected segment.
+func (p *SLRUPolicy[K]) OnUpdate(key K) {
+	p.promote(key)
+}
+
+// OnDelete implements EvictionPolicy.
+func (p *SLRUPolicy[K]) OnDelete(key K) {
+	if e, ok := p.entries[key]; ok {
+		p.protected.remove(e)
+		delete(p.entries, key)
+	}
+}
+
+// Victim implements EvictionPolicy. It returns the front key.
+func (p *SLRUPolicy[K]) Victim() (K, bool) {
+	return p.o.Front()
+}
+
+// promote moves key to the back of the map, which is the most recently used
+// end of the protected segment, and demotes keys from the other end while the
+// segment is too large. A demoted key stays where it is in the map, which
+// makes it the most recently used key of probation.
+func (p *SLRUPolicy[K]) promote(key K) {
+	p.o.MoveToBack(key)
+	if e, ok := p.entries[key]; ok {
+		e.moveTo(&p.protected)
+		return
+	}
+
+	e := &keyEntry[K]{key: key}
+	p.entries[key] = e
+	p.protected.push(e)
+
+	size := p.protectedSize()
+	for p.protected.len > size {
+		e := p.protected.head
+		p.protected.remove(e)
+		delete(p.entries, e.key)
+	}
+}
+
+// protectedSize returns the number of keys the protected segment holds. It is
+// unlimited in an unbounded map.
+func (p *SLRUPolicy[K]) protectedSize() int {
+	c := p.o.Capacity()
+	if c < 0 {
+		return math.MaxInt
+	}
+
+	return c - int(math.Ceil(float64(c)*p.probation))
+}
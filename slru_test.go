@@ -0,0 +1,121 @@
+package ringmap_test
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSLRUPolicy(t *testing.T) {
+	t.Run("Segments", func(t *testing.T) {
+		p := ringmap.NewSLRUPolicy[string](0.5)
+		m := ringmap.NewRingMapWithPolicy[string, int](4, p)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Get("a")
+		assert.Equal(t, []string{"b", "a"}, m.Keys())
+		assert.Equal(t, 1, p.ProtectedLen())
+
+		// New keys go to probation, in front of the protected segment.
+		m.Set("c", 3)
+		assert.Equal(t, []string{"b", "c", "a"}, m.Keys())
+		assert.Equal(t, "b", m.FrontEntry().Key())
+	})
+
+	t.Run("Demotion", func(t *testing.T) {
+		p := ringmap.NewSLRUPolicy[string](0.5)
+		m := ringmap.NewRingMapWithPolicy[string, int](4, p)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		m.Set("d", 4)
+		m.Get("a")
+		m.Get("b")
+		assert.Equal(t, []string{"c", "d", "a", "b"}, m.Keys())
+
+		// The protected segment holds two keys, so a goes back to the most
+		// recently used end of probation.
+		m.Get("c")
+		assert.Equal(t, []string{"d", "a", "b", "c"}, m.Keys())
+		assert.Equal(t, 2, p.ProtectedLen())
+
+		m.Set("e", 5)
+		assert.Equal(t, []string{"a", "e", "b", "c"}, m.Keys())
+
+		// A demoted key is promoted again when it's used.
+		m.Set("a", 6)
+		assert.Equal(t, []string{"e", "b", "c", "a"}, m.Keys())
+		assert.Equal(t, 2, p.ProtectedLen())
+	})
+
+	t.Run("PutStartsOver", func(t *testing.T) {
+		p := ringmap.NewSLRUPolicy[string](0.5)
+		m := ringmap.NewRingMapWithPolicy[string, int](4, p)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Get("a")
+		m.Put("a", 3)
+		assert.Equal(t, []string{"b", "a"}, m.Keys())
+		assert.Equal(t, 0, p.ProtectedLen())
+
+		m.Get("b")
+		m.Delete("b")
+		assert.Equal(t, 0, p.ProtectedLen())
+	})
+
+	t.Run("DefaultProbation", func(t *testing.T) {
+		for _, probation := range []float64{0, -1, 1, 2} {
+			p := ringmap.NewSLRUPolicy[int](probation)
+			m := ringmap.NewRingMapWithPolicy[int, int](10, p)
+			for i := 0; i < 10; i++ {
+				m.Set(i, i)
+				m.Get(i)
+			}
+			assert.Equal(t, 8, p.ProtectedLen())
+		}
+	})
+
+	t.Run("FrontIsVictim", func(t *testing.T) {
+		m := ringmap.NewSLRURingMapOf[string, int](10, 0.3)
+		evictions := recordEvictions(m)
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 10000; i++ {
+			key := strconv.Itoa(r.Intn(30))
+			if _, ok := m.Get(key); ok {
+				continue
+			}
+
+			front := m.FrontEntry()
+			full := m.Len() == m.Capacity()
+			m.Set(key, i)
+			if full {
+				last := (*evictions)[len(*evictions)-1]
+				if !assert.Equal(t, front.Key(), last.key, "step %d", i) {
+					return
+				}
+			}
+		}
+	})
+}
+
+func TestSLRUPolicy_Trace(t *testing.T) {
+	t.Run("ScanKeepsHotset", func(t *testing.T) {
+		hot := keySet("hot", 50)
+		trace := append(loop(hot, 3), keySet("scan", 1000)...)
+
+		m := ringmap.NewSLRURingMapOf[string, int](100, ringmap.DefaultSLRUProbation)
+		replay(m, trace)
+		assert.Equal(t, 1.0, replay(m, hot))
+	})
+
+	t.Run("HotsetBeatsLRU", func(t *testing.T) {
+		trace := hotsetTrace(rand.New(rand.NewSource(1)), 20000, 50, "")
+
+		lru := replay(ringmap.NewLRURingMapOf[string, int](100), trace)
+		slru := replay(ringmap.NewSLRURingMapOf[string, int](100, ringmap.DefaultSLRUProbation), trace)
+		assert.Greater(t, slru, lru+0.05)
+	})
+}
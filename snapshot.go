@@ -0,0 +1,402 @@
+package ringmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Codec turns keys or values of type T into bytes and back, for snapshots.
+type Codec[T any] interface {
+	Marshal(v T) ([]byte, error)
+	Unmarshal(data []byte, v *T) error
+}
+
+// GobCodec is the default Codec. It encodes every key or value on its own, so
+// each carries its own type information. Like with gob itself, the concrete
+// types stored in interface keys or values must be registered with
+// gob.Register.
+type GobCodec[T any] struct{}
+
+// Marshal encodes v with gob.
+func (GobCodec[T]) Marshal(v T) ([]byte, error) {
+	var buf bytes.Buffer
+	// Encoding a pointer keeps interface types intact.
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes data written by Marshal into v.
+func (GobCodec[T]) Unmarshal(data []byte, v *T) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// snapshotMagic starts every snapshot, followed by snapshotVersion.
+var snapshotMagic = [4]byte{'R', 'M', 'A', 'P'}
+
+const snapshotVersion = 1
+
+// snapshotTable is the CRC-32 table of the checksum that ends every snapshot.
+var snapshotTable = crc32.MakeTable(crc32.Castagnoli)
+
+// SetCodec sets the codecs that WriteTo and ReadFrom use for keys and values.
+// A nil codec restores the default GobCodec.
+func (m *RingMapOf[K, V]) SetCodec(keys Codec[K], values Codec[V]) {
+	m.keyCodec = keys
+	m.valueCodec = values
+}
+
+func (m *RingMapOf[K, V]) codecs() (Codec[K], Codec[V]) {
+	keys, values := m.keyCodec, m.valueCodec
+	if keys == nil {
+		keys = GobCodec[K]{}
+	}
+	if values == nil {
+		values = GobCodec[V]{}
+	}
+
+	return keys, values
+}
+
+// WriteTo writes a snapshot of the map to w. The snapshot holds the capacity of
+// the map and its live entries from Front() to Back(), with their expiry times,
+// followed by a checksum. Keys and values are encoded with the codecs set by
+// SetCodec, gob by default.
+func (m *RingMapOf[K, V]) WriteTo(w io.Writer) (int64, error) {
+	keys, values := m.codecs()
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	sum := crc32.New(snapshotTable)
+	out := io.MultiWriter(bw, sum)
+
+	var buf [binary.MaxVarintLen64]byte
+	putUvarint := func(x uint64) {
+		out.Write(buf[:binary.PutUvarint(buf[:], x)])
+	}
+	putVarint := func(x int64) {
+		out.Write(buf[:binary.PutVarint(buf[:], x)])
+	}
+
+	out.Write(snapshotMagic[:])
+	out.Write([]byte{snapshotVersion})
+	putVarint(int64(m.capacity))
+
+	now := m.clock.Now()
+	putUvarint(uint64(m.liveLen(now)))
+	for i := m.slots.front; i != none; i = m.slots.at(i).next {
+		if m.isExpired(i, now) {
+			continue
+		}
+
+		s := m.slots.at(i)
+		key, err := keys.Marshal(s.key)
+		if err != nil {
+			return cw.n, fmt.Errorf("ringmap: encoding key: %w", err)
+		}
+		value, err := values.Marshal(s.value)
+		if err != nil {
+			return cw.n, fmt.Errorf("ringmap: encoding value: %w", err)
+		}
+
+		var expires int64
+		if !s.expires.IsZero() {
+			expires = s.expires.UnixNano()
+		}
+		putVarint(expires)
+		putUvarint(uint64(len(key)))
+		out.Write(key)
+		putUvarint(uint64(len(value)))
+		out.Write(value)
+	}
+
+	binary.BigEndian.PutUint32(buf[:4], sum.Sum32())
+	bw.Write(buf[:4])
+	err := bw.Flush()
+
+	return cw.n, err
+}
+
+// liveLen returns the number of entries that haven't expired at now.
+func (m *RingMapOf[K, V]) liveLen(now time.Time) int {
+	if m.expiring == 0 {
+		return len(m.items)
+	}
+
+	n := 0
+	for i := m.slots.front; i != none; i = m.slots.at(i).next {
+		if !m.isExpired(i, now) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// snapshotEntry is an entry read from a snapshot.
+type snapshotEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// ReadFrom replaces the entries of the map with the ones in a snapshot written
+// by WriteTo, reading r until EOF. The entries are added in order, keeping
+// their expiry times, and entries that have expired since are left out. Like
+// with UnmarshalJSON, a map with a smaller capacity than the snapshot evicts
+// entries like Set does, the entries that were in the map are deleted first,
+// and a zero RingMapOf becomes a FIFO map with the capacity of the snapshot.
+//
+// The whole snapshot is read and checked before the map is changed. If it is
+// truncated, corrupted or of an unknown version, ReadFrom returns an error
+// wrapping ErrInvalidSnapshot and leaves the map unchanged.
+func (m *RingMapOf[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	n := int64(len(data))
+	if err != nil {
+		return n, err
+	}
+
+	keys, values := m.codecs()
+	capacity, entries, err := decodeSnapshot(data, keys, values)
+	if err != nil {
+		return n, err
+	}
+
+	if m.items == nil {
+		m.init(capacity, NewFIFOPolicy[K]())
+	}
+	m.deleteAll()
+	now := m.clock.Now()
+	for _, entry := range entries {
+		if !entry.expires.IsZero() && !now.Before(entry.expires) {
+			continue
+		}
+		m.set(entry.key, entry.value, entry.expires)
+	}
+
+	return n, nil
+}
+
+// decodeSnapshot checks and decodes a snapshot.
+func decodeSnapshot[K comparable, V any](data []byte, keys Codec[K], values Codec[V]) (int, []snapshotEntry[K, V], error) {
+	header := len(snapshotMagic) + 1
+	if len(data) < header+crc32.Size {
+		n := len(data)
+		if n > len(snapshotMagic) {
+			n = len(snapshotMagic)
+		}
+		if bytes.Equal(data[:n], snapshotMagic[:n]) {
+			return 0, nil, fmt.Errorf("%w: truncated", ErrInvalidSnapshot)
+		}
+		return 0, nil, fmt.Errorf("%w: not a snapshot", ErrInvalidSnapshot)
+	}
+	if !bytes.Equal(data[:len(snapshotMagic)], snapshotMagic[:]) {
+		return 0, nil, fmt.Errorf("%w: not a snapshot", ErrInvalidSnapshot)
+	}
+	if version := data[len(snapshotMagic)]; version != snapshotVersion {
+		return 0, nil, fmt.Errorf("%w: unknown version %d", ErrInvalidSnapshot, version)
+	}
+
+	body, trailer := data[:len(data)-crc32.Size], data[len(data)-crc32.Size:]
+	if crc32.Checksum(body, snapshotTable) != binary.BigEndian.Uint32(trailer) {
+		return 0, nil, fmt.Errorf("%w: checksum mismatch, the snapshot is truncated or corrupted", ErrInvalidSnapshot)
+	}
+
+	d := snapshotDecoder{data: body[header:]}
+	capacity := d.varint()
+	count := d.uvarint()
+	if d.err != nil {
+		return 0, nil, d.err
+	}
+	if count > uint64(len(d.data)) {
+		// Every entry takes at least a byte, so this can't be right.
+		return 0, nil, fmt.Errorf("%w: bad entry count %d", ErrInvalidSnapshot, count)
+	}
+
+	entries := make([]snapshotEntry[K, V], count)
+	for i := range entries {
+		expires := d.varint()
+		key := d.bytes()
+		value := d.bytes()
+		if d.err != nil {
+			return 0, nil, d.err
+		}
+
+		entry := &entries[i]
+		if expires != 0 {
+			entry.expires = time.Unix(0, expires)
+		}
+		if err := keys.Unmarshal(key, &entry.key); err != nil {
+			return 0, nil, fmt.Errorf("ringmap: decoding key: %w", err)
+		}
+		if err := values.Unmarshal(value, &entry.value); err != nil {
+			return 0, nil, fmt.Errorf("ringmap: decoding value: %w", err)
+		}
+	}
+	if len(d.data) != 0 {
+		return 0, nil, fmt.Errorf("%w: %d bytes after the last entry", ErrInvalidSnapshot, len(d.data))
+	}
+
+	return int(capacity), entries, nil
+}
+
+// snapshotDecoder reads the fields of a snapshot. After the first error it
+// reads nothing and keeps the error in err.
+type snapshotDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *snapshotDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	x, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = fmt.Errorf("%w: bad varint", ErrInvalidSnapshot)
+		return 0
+	}
+	d.data = d.data[n:]
+
+	return x
+}
+
+func (d *snapshotDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+
+	x, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.err = fmt.Errorf("%w: bad varint", ErrInvalidSnapshot)
+		return 0
+	}
+	d.data = d.data[n:]
+
+	return x
+}
+
+func (d *snapshotDecoder) bytes() []byte {
+	n := d.uvarint()
+	if d.err != nil {
+		return nil
+	}
+	if n > uint64(len(d.data)) {
+		d.err = fmt.Errorf("%w: entry runs past the end", ErrInvalidSnapshot)
+		return nil
+	}
+
+	b := d.data[:n]
+	d.data = d.data[n:]
+
+	return b
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// SaveFile writes a snapshot of the map to the named file. The snapshot is
+// written to a temporary file in the same directory first, which then replaces
+// the named file, so a crash never leaves a partial snapshot behind.
+func (m *RingMapOf[K, V]) SaveFile(name string) error {
+	return saveFile(name, m.WriteTo)
+}
+
+// LoadFile replaces the entries of the map with the snapshot in the named
+// file. See ReadFrom.
+func (m *RingMapOf[K, V]) LoadFile(name string) error {
+	return loadFile(name, m.ReadFrom)
+}
+
+func saveFile(name string, writeTo func(io.Writer) (int64, error)) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := writeTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), name)
+}
+
+func loadFile(name string, readFrom func(io.Reader) (int64, error)) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = readFrom(f)
+	return err
+}
+
+// WriteTo writes a snapshot of the map to w under the read lock. See
+// RingMapOf.WriteTo.
+func (s *SyncRingMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.WriteTo(w)
+}
+
+// ReadFrom replaces the entries of the map with a snapshot under the write
+// lock. See RingMapOf.ReadFrom.
+func (s *SyncRingMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.ReadFrom(r)
+}
+
+// SaveFile writes a snapshot of the map to the named file. See
+// RingMapOf.SaveFile.
+func (s *SyncRingMap[K, V]) SaveFile(name string) error {
+	return saveFile(name, s.WriteTo)
+}
+
+// LoadFile replaces the entries of the map with the snapshot in the named
+// file. See RingMapOf.ReadFrom.
+func (s *SyncRingMap[K, V]) LoadFile(name string) error {
+	return loadFile(name, s.ReadFrom)
+}
+
+// SetCodec sets the codecs used for snapshots. See RingMapOf.SetCodec.
+func (s *SyncRingMap[K, V]) SetCodec(keys Codec[K], values Codec[V]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.m.SetCodec(keys, values)
+}
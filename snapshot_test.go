@@ -0,0 +1,231 @@
+package ringmap_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+// decimalCodec encodes ints as decimal strings.
+type decimalCodec struct{}
+
+func (decimalCodec) Marshal(v int) ([]byte, error) {
+	return []byte(strconv.Itoa(v)), nil
+}
+
+func (decimalCodec) Unmarshal(data []byte, v *int) (err error) {
+	*v, err = strconv.Atoi(string(data))
+	return err
+}
+
+func newSnapshotMap() *ringmap.RingMapOf[string, int] {
+	m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+	for i, key := range []string{"z", "a", "m", "b"} {
+		m.Set(key, i)
+	}
+
+	return m
+}
+
+func writeSnapshot(t *testing.T, m *ringmap.RingMapOf[string, int]) []byte {
+	var buf bytes.Buffer
+	n, err := m.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	return buf.Bytes()
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		m := newSnapshotMap()
+		data := writeSnapshot(t, m)
+
+		n := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		read, err := n.ReadFrom(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(data)), read)
+		assert.Equal(t, []string{"z", "a", "m", "b"}, n.Keys())
+		value, _ := n.Get("m")
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("ReplacesEntries", func(t *testing.T) {
+		data := writeSnapshot(t, newSnapshotMap())
+
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.Set("old", 0)
+		_, err := m.ReadFrom(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"z", "a", "m", "b"}, m.Keys())
+	})
+
+	t.Run("TruncatesToCapacity", func(t *testing.T) {
+		data := writeSnapshot(t, newSnapshotMap())
+
+		m := ringmap.NewRingMapOf[string, int](2)
+		_, err := m.ReadFrom(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"m", "b"}, m.Keys())
+	})
+
+	t.Run("ZeroMapTakesCapacity", func(t *testing.T) {
+		data := writeSnapshot(t, newSnapshotMap())
+
+		var m ringmap.RingMapOf[string, int]
+		_, err := m.ReadFrom(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, ringMapCapacity, m.Capacity())
+		assert.Equal(t, 4, m.Len())
+	})
+
+	t.Run("KeepsExpiryTimes", func(t *testing.T) {
+		m, clock := newTTLMap(ringMapCapacity)
+		m.Set("forever", 1)
+		m.SetWithTTL("minute", 2, time.Minute)
+		m.SetWithTTL("gone", 3, time.Second)
+		clock.Advance(time.Second)
+		data := writeSnapshot(t, m)
+
+		n, _ := newTTLMap(ringMapCapacity)
+		n.SetClock(clock)
+		_, err := n.ReadFrom(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"forever", "minute"}, n.Keys())
+
+		clock.Advance(time.Minute)
+		assert.Equal(t, []string{"forever"}, n.Keys())
+
+		// Entries that expired while the snapshot was on disk are left out.
+		o, _ := newTTLMap(ringMapCapacity)
+		o.SetClock(clock)
+		_, err = o.ReadFrom(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"forever"}, o.Keys())
+		assert.Equal(t, 1, o.Len())
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		data := writeSnapshot(t, newSnapshotMap())
+
+		for i := 0; i < len(data); i++ {
+			m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+			m.Set("keep", 1)
+			_, err := m.ReadFrom(bytes.NewReader(data[:i]))
+			assert.ErrorIs(t, err, ringmap.ErrInvalidSnapshot, "truncated to %d bytes", i)
+			assert.Equal(t, []string{"keep"}, m.Keys())
+		}
+	})
+
+	t.Run("Corrupted", func(t *testing.T) {
+		data := writeSnapshot(t, newSnapshotMap())
+
+		for i := 0; i < len(data); i++ {
+			corrupted := append([]byte(nil), data...)
+			corrupted[i] ^= 0x40
+			m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+			m.Set("keep", 1)
+			_, err := m.ReadFrom(bytes.NewReader(corrupted))
+			assert.ErrorIs(t, err, ringmap.ErrInvalidSnapshot, "byte %d flipped", i)
+			assert.Equal(t, []string{"keep"}, m.Keys())
+		}
+	})
+
+	t.Run("TrailingData", func(t *testing.T) {
+		data := writeSnapshot(t, newSnapshotMap())
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		_, err := m.ReadFrom(bytes.NewReader(append(data, 0)))
+		assert.ErrorIs(t, err, ringmap.ErrInvalidSnapshot)
+	})
+
+	t.Run("NotASnapshot", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		_, err := m.ReadFrom(bytes.NewReader([]byte(`{"a":1}`)))
+		assert.ErrorIs(t, err, ringmap.ErrInvalidSnapshot)
+	})
+
+	t.Run("UnknownVersion", func(t *testing.T) {
+		data := writeSnapshot(t, newSnapshotMap())
+		data[4] = 99
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		_, err := m.ReadFrom(bytes.NewReader(data))
+		assert.ErrorIs(t, err, ringmap.ErrInvalidSnapshot)
+		assert.Contains(t, err.Error(), "version 99")
+	})
+
+	t.Run("Codec", func(t *testing.T) {
+		m, err := ringmap.New[string, int](
+			ringmap.WithCapacity(ringMapCapacity),
+			ringmap.WithCodec[string, int](nil, decimalCodec{}),
+		)
+		assert.NoError(t, err)
+		m.Set("a", 12345)
+		data := writeSnapshot(t, m)
+		assert.True(t, bytes.Contains(data, []byte("12345")))
+
+		n := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		n.SetCodec(nil, decimalCodec{})
+		_, err = n.ReadFrom(bytes.NewReader(data))
+		assert.NoError(t, err)
+		value, _ := n.Get("a")
+		assert.Equal(t, 12345, value)
+	})
+
+	t.Run("CodecErrors", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.SetCodec(nil, decimalCodec{})
+		m.Set("a", 1)
+		data := writeSnapshot(t, m)
+
+		// Decoding "1" with gob fails.
+		n := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		n.Set("keep", 1)
+		_, err := n.ReadFrom(bytes.NewReader(data))
+		assert.Error(t, err)
+		assert.Equal(t, []string{"keep"}, n.Keys())
+	})
+
+	t.Run("Untyped", func(t *testing.T) {
+		gob.Register(jsonPoint{})
+		m := ringmap.NewRingMap(ringMapCapacity)
+		m.Set("a", 1)
+		m.Set(2, jsonPoint{3, 4})
+
+		var buf bytes.Buffer
+		_, err := m.WriteTo(&buf)
+		assert.NoError(t, err)
+
+		n := ringmap.NewRingMap(ringMapCapacity)
+		_, err = n.ReadFrom(&buf)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"a", 2}, n.Keys())
+		value, _ := n.Get(2)
+		assert.Equal(t, jsonPoint{3, 4}, value)
+	})
+
+	t.Run("Files", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "ring.snapshot")
+		assert.NoError(t, newSnapshotMap().SaveFile(name))
+
+		m := ringmap.NewSyncRingMap[string, int](ringMapCapacity)
+		assert.NoError(t, m.LoadFile(name))
+		assert.Equal(t, []string{"z", "a", "m", "b"}, m.Keys())
+
+		m.Set("c", 5)
+		assert.NoError(t, m.SaveFile(name))
+		n := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		assert.NoError(t, n.LoadFile(name))
+		assert.Equal(t, []string{"z", "a", "m", "b", "c"}, n.Keys())
+
+		err := n.LoadFile(filepath.Join(t.TempDir(), "missing"))
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ringmap.ErrInvalidSnapshot))
+	})
+}
@@ -0,0 +1,103 @@
+package ringmap
+
+import "sync/atomic"
+
+// Stats is a snapshot of the counters of a map.
+type Stats struct {
+	// Hits and Misses count the lookups by Get and GetOrDefault.
+	Hits   uint64
+	Misses uint64
+
+	// Inserts counts the keys added by Set and Put, and Updates the values
+	// they replaced.
+	Inserts uint64
+	Updates uint64
+
+	// Evictions counts the entries that left the map, by reason.
+	Evictions map[EvictReason]uint64
+
+	// PeakLen is the largest number of elements the map has held, including
+	// expired ones that hadn't been removed yet.
+	PeakLen int
+}
+
+// HitRatio returns the fraction of lookups that were hits, or 0 if there were
+// none.
+func (s Stats) HitRatio() float64 {
+	lookups := s.Hits + s.Misses
+	if lookups == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(lookups)
+}
+
+// stats holds the counters of a map. They are atomic, so lookups that run
+// concurrently under a read lock can count.
+type stats struct {
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	inserts   atomic.Uint64
+	updates   atomic.Uint64
+	evictions [EvictExpired + 1]atomic.Uint64
+	peakLen   atomic.Int64
+}
+
+// Stats returns a snapshot of the counters of the map.
+func (m *RingMapOf[K, V]) Stats() Stats {
+	s := Stats{
+		Hits:      m.stats.hits.Load(),
+		Misses:    m.stats.misses.Load(),
+		Inserts:   m.stats.inserts.Load(),
+		Updates:   m.stats.updates.Load(),
+		Evictions: make(map[EvictReason]uint64, len(m.stats.evictions)),
+		PeakLen:   int(m.stats.peakLen.Load()),
+	}
+	for reason := range m.stats.evictions {
+		s.Evictions[EvictReason(reason)] = m.stats.evictions[reason].Load()
+	}
+
+	return s
+}
+
+// ResetStats sets the counters of the map to zero, and the peak length to the
+// current length.
+func (m *RingMapOf[K, V]) ResetStats() {
+	m.stats.hits.Store(0)
+	m.stats.misses.Store(0)
+	m.stats.inserts.Store(0)
+	m.stats.updates.Store(0)
+	for reason := range m.stats.evictions {
+		m.stats.evictions[reason].Store(0)
+	}
+	m.stats.peakLen.Store(int64(len(m.items)))
+}
+
+// countLookup counts a hit or a miss.
+func (m *RingMapOf[K, V]) countLookup(hit bool) {
+	if hit {
+		m.stats.hits.Add(1)
+	} else {
+		m.stats.misses.Add(1)
+	}
+}
+
+// countWrite counts an insert or an update, and keeps the peak length.
+func (m *RingMapOf[K, V]) countWrite(inserted bool) {
+	if !inserted {
+		m.stats.updates.Add(1)
+		return
+	}
+
+	m.stats.inserts.Add(1)
+	if n := int64(len(m.items)); n > m.stats.peakLen.Load() {
+		m.stats.peakLen.Store(n)
+	}
+}
+
+// countEviction counts an entry that left the map.
+func (m *RingMapOf[K, V]) countEviction(reason EvictReason) {
+	if int(reason) < len(m.stats.evictions) {
+		m.stats.evictions[reason].Add(1)
+	}
+}
@@ -0,0 +1,143 @@
+package ringmap_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("Lookups", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.Set("a", 1)
+
+		m.Get("a")
+		m.Get("b")
+		m.GetOrDefault("a", 0)
+		m.GetOrDefault("b", 0)
+		m.Get("c")
+		m.Peek("a")
+
+		stats := m.Stats()
+		assert.Equal(t, uint64(2), stats.Hits)
+		assert.Equal(t, uint64(3), stats.Misses)
+		assert.InDelta(t, 0.4, stats.HitRatio(), 1e-9)
+	})
+
+	t.Run("Writes", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](2)
+		m.Set("a", 1)
+		m.Put("b", 2)
+		m.Set("a", 3)
+		m.Put("b", 4)
+		m.Set("c", 5)
+		m.Delete("c")
+		m.Delete("c")
+
+		stats := m.Stats()
+		assert.Equal(t, uint64(3), stats.Inserts)
+		assert.Equal(t, uint64(2), stats.Updates)
+		assert.Equal(t, map[ringmap.EvictReason]uint64{
+			ringmap.EvictCapacity: 1,
+			ringmap.EvictDeleted:  1,
+			ringmap.EvictReplaced: 2,
+			ringmap.EvictExpired:  0,
+		}, stats.Evictions)
+		assert.Equal(t, 2, stats.PeakLen)
+	})
+
+	t.Run("RejectedWritesArentCounted", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](0)
+		m.Set("a", 1)
+		m.Put("a", 1)
+		assert.Equal(t, uint64(0), m.Stats().Inserts)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		m, clock := newTTLMap(ringMapCapacity)
+		m.SetWithTTL("a", 1, time.Minute)
+		clock.Advance(time.Minute)
+
+		m.Get("a")
+		m.Set("a", 2)
+		stats := m.Stats()
+		assert.Equal(t, uint64(1), stats.Misses)
+		assert.Equal(t, uint64(2), stats.Inserts)
+		assert.Equal(t, uint64(0), stats.Updates)
+		assert.Equal(t, uint64(1), stats.Evictions[ringmap.EvictExpired])
+	})
+
+	t.Run("PeakLen", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[int, int](ringMapCapacity)
+		for i := 0; i < 5; i++ {
+			m.Set(i, i)
+		}
+		for i := 0; i < 3; i++ {
+			m.Delete(i)
+		}
+		assert.Equal(t, 5, m.Stats().PeakLen)
+
+		m.ResetStats()
+		assert.Equal(t, 2, m.Stats().PeakLen)
+	})
+
+	t.Run("ResetStats", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](1)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Get("b")
+		m.Get("a")
+
+		m.ResetStats()
+		stats := m.Stats()
+		assert.Equal(t, uint64(0), stats.Hits)
+		assert.Equal(t, uint64(0), stats.Misses)
+		assert.Equal(t, uint64(0), stats.Inserts)
+		assert.Equal(t, uint64(0), stats.Evictions[ringmap.EvictCapacity])
+		assert.Equal(t, 0.0, stats.HitRatio())
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[int, int](ringMapCapacity)
+		for i := 0; i < 10; i++ {
+			m.Set(i, i)
+		}
+
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					m.Get(i % 20)
+				}
+			}()
+		}
+		wg.Wait()
+
+		stats := m.Stats()
+		assert.Equal(t, uint64(4000), stats.Hits)
+		assert.Equal(t, uint64(4000), stats.Misses)
+	})
+
+	t.Run("Sharded", func(t *testing.T) {
+		m := ringmap.NewShardedRingMap[int, int](ringMapCapacity, 4)
+		for i := 0; i < 10; i++ {
+			m.Set(i, i)
+			m.Get(i)
+		}
+		m.Get(-1)
+
+		stats := m.Stats()
+		assert.Equal(t, uint64(10), stats.Inserts)
+		assert.Equal(t, uint64(10), stats.Hits)
+		assert.Equal(t, uint64(1), stats.Misses)
+		assert.Equal(t, 10, stats.PeakLen)
+
+		m.ResetStats()
+		assert.Equal(t, uint64(0), m.Stats().Hits)
+	})
+}
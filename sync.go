@@ -0,0 +1,358 @@
+package ringmap
+
+import (
+	"sync"
+	"time"
+)
+
+// SyncRingMap is a RingMap that is safe for concurrent use by multiple
+// goroutines. Reads share a read lock and writes take an exclusive lock. Get
+// and GetOrDefault only share the read lock if the eviction policy implements
+// ConcurrentAccessPolicy, as FIFOPolicy, SievePolicy and ClockPolicy do; other
+// policies, like the LRU one, may reorder the map on access, so those reads
+// take the exclusive lock. Peek always shares the read lock.
+//
+// Elements are never handed out, since walking an element chain outside of the
+// lock would race with writers. Use Range, RangeReverse or Do to iterate.
+type SyncRingMap[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  *RingMapOf[K, V]
+}
+
+// NewSyncRingMap creates a new concurrent ordered map with a maximum size.
+func NewSyncRingMap[K comparable, V any](capacity int) *SyncRingMap[K, V] {
+	return Synchronize(NewRingMapOf[K, V](capacity))
+}
+
+// NewSyncLRURingMap creates a new concurrent ordered map in LRU mode with a
+// maximum size.
+func NewSyncLRURingMap[K comparable, V any](capacity int) *SyncRingMap[K, V] {
+	return Synchronize(NewLRURingMapOf[K, V](capacity))
+}
+
+// Synchronize wraps m in a SyncRingMap. The caller must not use m directly
+// afterwards.
+func Synchronize[K comparable, V any](m *RingMapOf[K, V]) *SyncRingMap[K, V] {
+	return &SyncRingMap[K, V]{m: m}
+}
+
+// OnEvict sets the function that is called for every entry that leaves the
+// map. See RingMapOf.OnEvict. fn is called while the lock is held and must not
+// use s.
+func (s *SyncRingMap[K, V]) OnEvict(fn EvictFunc[K, V]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.m.OnEvict(fn)
+}
+
+// lockAccess locks s for a read that may reorder the map, and returns the
+// matching unlock function.
+func (s *SyncRingMap[K, V]) lockAccess() (unlock func()) {
+	if p, ok := s.m.Policy().(ConcurrentAccessPolicy); !ok || !p.ConcurrentAccess() {
+		s.mu.Lock()
+		return s.mu.Unlock
+	}
+
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+// Get returns the value for a key. If the key does not exist, the second return
+// parameter will be false and the value will be the zero value of V.
+func (s *SyncRingMap[K, V]) Get(key K) (V, bool) {
+	defer s.lockAccess()()
+
+	return s.m.Get(key)
+}
+
+// Peek returns the value for a key like Get, but never moves the element.
+func (s *SyncRingMap[K, V]) Peek(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.Peek(key)
+}
+
+// GetOrDefault returns the value for a key. If the key does not exist, returns
+// the default value instead.
+func (s *SyncRingMap[K, V]) GetOrDefault(key K, defaultValue V) V {
+	defer s.lockAccess()()
+
+	return s.m.GetOrDefault(key, defaultValue)
+}
+
+// Set will set (or replace) a value for a key. See RingMapOf.Set.
+func (s *SyncRingMap[K, V]) Set(key K, value V) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.Set(key, value)
+}
+
+// Put will set a value for a key, moving it to the back. See RingMapOf.Put.
+func (s *SyncRingMap[K, V]) Put(key K, value V) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.Put(key, value)
+}
+
+// TrySet is like Set, but returns an error if the key could not be added. See
+// RingMapOf.TrySet.
+func (s *SyncRingMap[K, V]) TrySet(key K, value V) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.TrySet(key, value)
+}
+
+// TryPut is like Put, but returns an error if the key could not be added. See
+// RingMapOf.TryPut.
+func (s *SyncRingMap[K, V]) TryPut(key K, value V) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.TryPut(key, value)
+}
+
+// SetWithTTL is like Set, but the entry expires after ttl. See
+// RingMapOf.SetWithTTL.
+func (s *SyncRingMap[K, V]) SetWithTTL(key K, value V, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.SetWithTTL(key, value, ttl)
+}
+
+// PutWithTTL is like Put, but the entry expires after ttl. See
+// RingMapOf.PutWithTTL.
+func (s *SyncRingMap[K, V]) PutWithTTL(key K, value V, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.PutWithTTL(key, value, ttl)
+}
+
+// SetDefaultTTL sets the time to live that Set and Put give to entries. See
+// RingMapOf.SetDefaultTTL.
+func (s *SyncRingMap[K, V]) SetDefaultTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.m.SetDefaultTTL(ttl)
+}
+
+// SetClock sets the clock the map uses to expire entries. The clock must be
+// safe for concurrent use.
+func (s *SyncRingMap[K, V]) SetClock(clock Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.m.SetClock(clock)
+}
+
+// PurgeExpired removes every expired entry and returns how many were removed.
+func (s *SyncRingMap[K, V]) PurgeExpired() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.PurgeExpired()
+}
+
+// Delete will remove a key from the map. It will return true if the key was
+// removed (the key did exist).
+func (s *SyncRingMap[K, V]) Delete(key K) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.Delete(key)
+}
+
+// Len returns the number of elements in the map.
+func (s *SyncRingMap[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.Len()
+}
+
+// Capacity returns the capacity of the map
+func (s *SyncRingMap[K, V]) Capacity() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.Capacity()
+}
+
+// Stats returns a snapshot of the counters of the map. See RingMapOf.Stats.
+func (s *SyncRingMap[K, V]) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.Stats()
+}
+
+// ResetStats sets the counters of the map to zero. See RingMapOf.ResetStats.
+func (s *SyncRingMap[K, V]) ResetStats() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	s.m.ResetStats()
+}
+
+// Weight returns the total weight of the entries in the map.
+func (s *SyncRingMap[K, V]) Weight() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.Weight()
+}
+
+// MaxWeight returns the maximum total weight of the entries in the map, or
+// Unbounded if there is none.
+func (s *SyncRingMap[K, V]) MaxWeight() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.MaxWeight()
+}
+
+// Resize changes the capacity of the map, evicting elements if it shrinks. See
+// RingMapOf.Resize.
+func (s *SyncRingMap[K, V]) Resize(capacity int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.Resize(capacity)
+}
+
+// IsFull returns true if the number of elements in the map is Capacity()
+func (s *SyncRingMap[K, V]) IsFull() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.IsFull()
+}
+
+// Keys returns a copy of all of the keys from Front to Back.
+func (s *SyncRingMap[K, V]) Keys() []K {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.Keys()
+}
+
+// Front returns the key and value of the first (oldest Set) element. The last
+// return parameter is false if the map is empty.
+func (s *SyncRingMap[K, V]) Front() (key K, value V, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if el := s.m.Front(); el != nil {
+		return el.Key, el.Value, true
+	}
+
+	return key, value, false
+}
+
+// Back returns the key and value of the last (most recent Set) element. The
+// last return parameter is false if the map is empty.
+func (s *SyncRingMap[K, V]) Back() (key K, value V, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if el := s.m.Back(); el != nil {
+		return el.Key, el.Value, true
+	}
+
+	return key, value, false
+}
+
+// GetOrSet returns the existing value for a key if it exists. Otherwise it sets
+// and returns the given value. The loaded result is true if the value was
+// already in the map.
+func (s *SyncRingMap[K, V]) GetOrSet(key K, value V) (actual V, loaded bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if actual, loaded = s.m.Get(key); loaded {
+		return actual, true
+	}
+	s.m.Set(key, value)
+
+	return value, false
+}
+
+// SetIfAbsent sets the value for a key only if the key does not exist. It
+// returns true if the value was set.
+func (s *SyncRingMap[K, V]) SetIfAbsent(key K, value V) bool {
+	_, loaded := s.GetOrSet(key, value)
+	return !loaded
+}
+
+// GetAndDelete removes a key from the map and returns the value it had. The
+// second return parameter is false if the key did not exist.
+func (s *SyncRingMap[K, V]) GetAndDelete(key K) (value V, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value, ok = s.m.Peek(key); ok {
+		s.m.Delete(key)
+	}
+
+	return value, ok
+}
+
+// Compute atomically replaces the value for a key with the result of fn. fn is
+// called with the current value and whether the key exists. If fn returns keep
+// as false the key is deleted, otherwise the new value is Set. Compute returns
+// the new value and whether the key exists afterwards.
+//
+// fn is called while the lock is held and must not use s.
+func (s *SyncRingMap[K, V]) Compute(key K, fn func(value V, exists bool) (newValue V, keep bool)) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, exists := s.m.Get(key)
+	newValue, keep := fn(value, exists)
+	if !keep {
+		s.m.Delete(key)
+		var zero V
+		return zero, false
+	}
+	s.m.Set(key, newValue)
+
+	return newValue, true
+}
+
+// Range calls f for each key and value from Front to Back while holding the
+// read lock, skipping expired entries. If f returns false, Range stops the
+// iteration.
+//
+// f must not use s, since that would deadlock with the held lock.
+func (s *SyncRingMap[K, V]) Range(f func(key K, value V) bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	s.m.rangeSlots(s.m.slots.front, true, f)
+}
+
+// RangeReverse is like Range but iterates from Back to Front.
+func (s *SyncRingMap[K, V]) RangeReverse(f func(key K, value V) bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	s.m.rangeSlots(s.m.slots.back, false, f)
+}
+
+// Do calls f with the underlying RingMapOf while holding the write lock, so that
+// any sequence of operations and element traversals in f is atomic.
+//
+// f must not use s, and must not keep m or its elements after returning.
+func (s *SyncRingMap[K, V]) Do(f func(m *RingMapOf[K, V])) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f(s.m)
+}
@@ -0,0 +1,268 @@
+package ringmap_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncRingMap(t *testing.T) {
+	t.Run("BehavesLikeRingMap", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[int, string](3)
+		assert.True(t, m.Set(1, "a"))
+		assert.True(t, m.Set(2, "b"))
+		assert.True(t, m.Put(3, "c"))
+		assert.True(t, m.IsFull())
+		assert.True(t, m.Set(4, "d"))
+		assert.Equal(t, []int{2, 3, 4}, m.Keys())
+		assert.Equal(t, 3, m.Len())
+		assert.Equal(t, 3, m.Capacity())
+
+		value, ok := m.Get(3)
+		assert.True(t, ok)
+		assert.Equal(t, "c", value)
+		assert.Equal(t, "z", m.GetOrDefault(1, "z"))
+
+		assert.True(t, m.Delete(3))
+		assert.False(t, m.Delete(3))
+	})
+
+	t.Run("FrontAndBack", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[int, string](ringMapCapacity)
+		_, _, ok := m.Front()
+		assert.False(t, ok)
+		_, _, ok = m.Back()
+		assert.False(t, ok)
+
+		m.Set(1, "a")
+		m.Set(2, "b")
+		key, value, ok := m.Front()
+		assert.Equal(t, []interface{}{1, "a", true}, []interface{}{key, value, ok})
+		key, value, ok = m.Back()
+		assert.Equal(t, []interface{}{2, "b", true}, []interface{}{key, value, ok})
+	})
+
+	t.Run("Synchronize", func(t *testing.T) {
+		inner := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		inner.Set("foo", 1)
+		m := ringmap.Synchronize(inner)
+		assert.Equal(t, 1, m.GetOrDefault("foo", 0))
+	})
+}
+
+func TestSyncRingMap_LRU(t *testing.T) {
+	m := ringmap.NewSyncLRURingMap[int, bool](3)
+	m.Set(1, true)
+	m.Set(2, true)
+	m.Set(3, true)
+	m.Get(1)
+	m.Peek(2)
+	m.Set(4, true)
+	assert.Equal(t, []int{3, 1, 4}, m.Keys())
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				m.Get(i % 5)
+				m.GetOrDefault(i%7, false)
+				m.Peek(i % 3)
+				m.Set(g, true)
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 3, m.Len())
+}
+
+func TestSyncRingMap_Compound(t *testing.T) {
+	t.Run("GetOrSet", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[string, int](ringMapCapacity)
+		actual, loaded := m.GetOrSet("foo", 1)
+		assert.Equal(t, 1, actual)
+		assert.False(t, loaded)
+
+		actual, loaded = m.GetOrSet("foo", 2)
+		assert.Equal(t, 1, actual)
+		assert.True(t, loaded)
+	})
+
+	t.Run("SetIfAbsent", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[string, int](ringMapCapacity)
+		assert.True(t, m.SetIfAbsent("foo", 1))
+		assert.False(t, m.SetIfAbsent("foo", 2))
+		assert.Equal(t, 1, m.GetOrDefault("foo", 0))
+	})
+
+	t.Run("GetAndDelete", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[string, int](ringMapCapacity)
+		m.Set("foo", 1)
+		value, ok := m.GetAndDelete("foo")
+		assert.Equal(t, 1, value)
+		assert.True(t, ok)
+
+		_, ok = m.GetAndDelete("foo")
+		assert.False(t, ok)
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("ComputeSetsAndDeletes", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[string, int](ringMapCapacity)
+		increment := func(value int, exists bool) (int, bool) {
+			return value + 1, true
+		}
+		m.Compute("foo", increment)
+		value, ok := m.Compute("foo", increment)
+		assert.Equal(t, 2, value)
+		assert.True(t, ok)
+
+		_, ok = m.Compute("foo", func(int, bool) (int, bool) {
+			return 0, false
+		})
+		assert.False(t, ok)
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("RangeInOrder", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[int, bool](ringMapCapacity)
+		for i := 1; i <= 5; i++ {
+			m.Set(i, true)
+		}
+
+		var forward, backward []int
+		m.Range(func(key int, _ bool) bool {
+			forward = append(forward, key)
+			return key < 3
+		})
+		m.RangeReverse(func(key int, _ bool) bool {
+			backward = append(backward, key)
+			return true
+		})
+		assert.Equal(t, []int{1, 2, 3}, forward)
+		assert.Equal(t, []int{5, 4, 3, 2, 1}, backward)
+	})
+
+	t.Run("RangeSkipsExpired", func(t *testing.T) {
+		tm, clock := newTTLMap(ringMapCapacity)
+		m := ringmap.Synchronize(tm)
+		m.SetWithTTL("a", 1, time.Minute)
+		m.Set("b", 2)
+		m.SetWithTTL("c", 3, time.Minute)
+		clock.Advance(time.Minute)
+
+		var forward, backward []string
+		m.Range(func(key string, _ int) bool {
+			forward = append(forward, key)
+			return true
+		})
+		m.RangeReverse(func(key string, _ int) bool {
+			backward = append(backward, key)
+			return true
+		})
+		assert.Equal(t, []string{"b"}, forward)
+		assert.Equal(t, []string{"b"}, backward)
+		assert.Equal(t, m.Keys(), forward)
+	})
+
+	t.Run("DoIsAtomic", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[int, bool](ringMapCapacity)
+		m.Do(func(m *ringmap.RingMapOf[int, bool]) {
+			m.Set(1, true)
+			m.Set(2, true)
+			m.Delete(m.Front().Key)
+		})
+		assert.Equal(t, []int{2}, m.Keys())
+	})
+}
+
+// TestSyncRingMap_Stress hammers a single map from many goroutines. Run it
+// with -race to have the race detector check the locking.
+func TestSyncRingMap_Stress(t *testing.T) {
+	const goroutines = 32
+	const iterations = 2000
+
+	t.Run("MixedOperations", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[string, int](64)
+
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < iterations; i++ {
+					key := strconv.Itoa((g*iterations + i) % 100)
+					switch i % 8 {
+					case 0:
+						m.Set(key, i)
+					case 1:
+						m.Get(key)
+					case 2:
+						m.Delete(key)
+					case 3:
+						m.GetOrSet(key, i)
+					case 4:
+						m.Keys()
+					case 5:
+						m.Range(func(string, int) bool { return true })
+					case 6:
+						m.Front()
+						m.Back()
+					case 7:
+						m.Len()
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		assert.True(t, m.Len() <= m.Capacity())
+		assert.Len(t, m.Keys(), m.Len())
+	})
+
+	t.Run("ComputeIsAtomic", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[string, int](ringMapCapacity)
+
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < iterations; i++ {
+					m.Compute("counter", func(value int, _ bool) (int, bool) {
+						return value + 1, true
+					})
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, goroutines*iterations, m.GetOrDefault("counter", 0))
+	})
+
+	t.Run("SetIfAbsentHasOneWinner", func(t *testing.T) {
+		m := ringmap.NewSyncRingMap[string, int](ringMapCapacity)
+
+		var wg sync.WaitGroup
+		winners := make(chan int, goroutines)
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				if m.SetIfAbsent("foo", g) {
+					winners <- g
+				}
+			}(g)
+		}
+		wg.Wait()
+		close(winners)
+
+		assert.Len(t, winners, 1)
+		assert.Equal(t, <-winners, m.GetOrDefault("foo", -1))
+	})
+}
@@ -0,0 +1,175 @@
+package ringmap
+
+import "math"
+
+// TinyLFUPolicy is the W-TinyLFU policy of Einziger, Friedman and Manes. It
+// keeps a full map from trading keys that are used again for keys that are
+// only used once, as scans do.
+//
+// New keys enter a small LRU window, which holds one percent of the capacity
+// and lets bursts of new keys build up some frequency. A key that drops out of
+// the window is a candidate for the main part of the map. When the map is
+// full, the policy estimates how often the candidate and the main part's
+// victim were used, and keeps the more frequent one: a newcomer that wasn't
+// used more than the victim is evicted instead of it.
+//
+// The frequencies are estimated from a count-min sketch of recent uses, with a
+// doorkeeper bloom filter in front of it, sized from the capacity of the map
+// when the policy is attached. Every insert, read with Get or GetOrDefault and
+// replacement with Set counts as a use. The sketch is halved periodically, so
+// it follows recent history.
+//
+// The main part is a segmented LRU. Admitted keys start in its probation
+// segment and move to the protected segment, which holds 80 percent of the
+// main part, once they're used again. The main part's victim is the LRU key of
+// the probation segment, or of the protected one if probation is empty.
+type TinyLFUPolicy[K comparable] struct {
+	o       Ordering[K]
+	hash    func(K) uint64
+	sketch  *sketch
+	entries map[K]*keyEntry[K]
+
+	window    keyList[K]
+	probation keyList[K]
+	protected keyList[K]
+}
+
+// NewTinyLFUPolicy creates a new W-TinyLFU eviction policy. Keys are hashed
+// with a hash that supports every comparable key type, like the one of
+// NewShardedRingMap.
+func NewTinyLFUPolicy[K comparable]() *TinyLFUPolicy[K] {
+	return NewTinyLFUPolicyWithHasher[K](newDefaultHasher[K]())
+}
+
+// NewTinyLFUPolicyWithHasher is like NewTinyLFUPolicy but hashes keys with
+// hash. Equal keys must have equal hashes.
+func NewTinyLFUPolicyWithHasher[K comparable](hash func(K) uint64) *TinyLFUPolicy[K] {
+	return &TinyLFUPolicy[K]{
+		hash:    hash,
+		entries: make(map[K]*keyEntry[K]),
+	}
+}
+
+// NewTinyLFURingMapOf creates a new ordered map in W-TinyLFU mode with a
+// maximum size that holds keys of type K and values of type V.
+func NewTinyLFURingMapOf[K comparable, V any](capacity int) *RingMapOf[K, V] {
+	return NewRingMapWithPolicy[K, V](capacity, NewTinyLFUPolicy[K]())
+}
+
+// Estimate returns the estimated number of recent uses of a key, whether it's
+// in the map or not. Estimates are capped at 16.
+func (p *TinyLFUPolicy[K]) Estimate(key K) int {
+	return p.sketch.estimate(p.hash(key))
+}
+
+// Attach implements EvictionPolicy.
+func (p *TinyLFUPolicy[K]) Attach(o Ordering[K]) {
+	p.o = o
+	p.sketch = newSketch(o.Capacity())
+}
+
+// OnInsert implements EvictionPolicy. The key enters the window, and the
+// window's LRU key moves to probation if the window is too large.
+func (p *TinyLFUPolicy[K]) OnInsert(key K) {
+	p.sketch.add(p.hash(key))
+
+	e := &keyEntry[K]{key: key}
+	p.entries[key] = e
+	p.window.push(e)
+	for p.window.len > p.windowSize() {
+		p.window.head.moveTo(&p.probation)
+	}
+}
+
+// OnAccess implements EvictionPolicy. It counts the use and promotes key
+// within its segment, or from probation to protected.
+func (p *TinyLFUPolicy[K]) OnAccess(key K) {
+	p.use(key)
+}
+
+// OnUpdate implements EvictionPolicy. It counts the use and promotes key
+// within its segment, or from probation to protected.
+func (p *TinyLFUPolicy[K]) OnUpdate(key K) {
+	p.use(key)
+}
+
+// OnDelete implements EvictionPolicy.
+func (p *TinyLFUPolicy[K]) OnDelete(key K) {
+	if e, ok := p.entries[key]; ok {
+		e.list.remove(e)
+		delete(p.entries, key)
+	}
+}
+
+// Victim implements EvictionPolicy. If the window is full, its LRU key is the
+// candidate for the main part, and the one of the candidate and the main
+// part's victim with the lower estimated frequency is returned. Ties go
+// against the candidate.
+func (p *TinyLFUPolicy[K]) Victim() (K, bool) {
+	victim := p.probation.head
+	if victim == nil {
+		victim = p.protected.head
+	}
+
+	var candidate *keyEntry[K]
+	if p.window.len >= p.windowSize() {
+		candidate = p.window.head
+	}
+
+	switch {
+	case candidate == nil && victim == nil:
+		victim = p.window.head
+	case candidate == nil:
+	case victim == nil || p.Estimate(candidate.key) <= p.Estimate(victim.key):
+		victim = candidate
+	}
+	if victim == nil {
+		var zero K
+		return zero, false
+	}
+
+	return victim.key, true
+}
+
+// use counts a use of key and moves it to the back of its segment. A key on
+// probation is promoted to the protected segment, whose LRU keys are demoted
+// back to probation if it grows too large.
+func (p *TinyLFUPolicy[K]) use(key K) {
+	p.sketch.add(p.hash(key))
+
+	e, ok := p.entries[key]
+	if !ok {
+		return
+	}
+
+	if e.list != &p.probation {
+		e.moveTo(e.list)
+		return
+	}
+
+	e.moveTo(&p.protected)
+	for p.protected.len > p.protectedSize() {
+		p.protected.head.moveTo(&p.probation)
+	}
+}
+
+// windowSize returns the number of keys the window holds, one percent of the
+// capacity but at least one.
+func (p *TinyLFUPolicy[K]) windowSize() int {
+	if size := p.o.Capacity() / 100; size > 1 {
+		return size
+	}
+
+	return 1
+}
+
+// protectedSize returns the number of keys the protected segment holds, 80
+// percent of the main part. It is unlimited in an unbounded map.
+func (p *TinyLFUPolicy[K]) protectedSize() int {
+	c := p.o.Capacity()
+	if c < 0 {
+		return math.MaxInt
+	}
+
+	return (c - p.windowSize()) * 8 / 10
+}
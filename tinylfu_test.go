@@ -0,0 +1,149 @@
+package ringmap_test
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func fnvHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+func TestTinyLFUPolicy(t *testing.T) {
+	t.Run("Estimate", func(t *testing.T) {
+		p := ringmap.NewTinyLFUPolicyWithHasher[string](fnvHash)
+		m := ringmap.NewRingMapWithPolicy[string, int](100, p)
+		assert.Equal(t, 0, p.Estimate("a"))
+
+		// The first use only goes to the doorkeeper.
+		m.Set("a", 1)
+		assert.Equal(t, 1, p.Estimate("a"))
+		m.Get("a")
+		m.Set("a", 2)
+		assert.Equal(t, 3, p.Estimate("a"))
+		m.Peek("a")
+		assert.Equal(t, 3, p.Estimate("a"))
+
+		for i := 0; i < 20; i++ {
+			m.Get("a")
+		}
+		assert.Equal(t, 16, p.Estimate("a"))
+		assert.Equal(t, 0, p.Estimate("b"))
+	})
+
+	t.Run("Aging", func(t *testing.T) {
+		p := ringmap.NewTinyLFUPolicyWithHasher[string](fnvHash)
+		m := ringmap.NewRingMapWithPolicy[string, int](100, p)
+		m.Set("a", 1)
+
+		// A sketch for 100 keys has 128 counters per row, and is halved
+		// after ten times as many uses.
+		for i := 1; i < 1279; i++ {
+			m.Get("a")
+		}
+		assert.Equal(t, 16, p.Estimate("a"))
+		m.Get("a")
+		assert.Equal(t, 7, p.Estimate("a"))
+	})
+
+	t.Run("DefaultHasher", func(t *testing.T) {
+		type node struct{ value int }
+		p := ringmap.NewTinyLFUPolicy[*node]()
+		m := ringmap.NewRingMapWithPolicy[*node, int](100, p)
+		key := &node{1}
+		m.Set(key, 1)
+		m.Get(key)
+
+		// The estimate follows the pointer, not what it points to.
+		key.value = 2
+		assert.Equal(t, 2, p.Estimate(key))
+		assert.Equal(t, 0, p.Estimate(&node{2}))
+	})
+
+	t.Run("NewKeyEntersWindow", func(t *testing.T) {
+		m := ringmap.NewTinyLFURingMapOf[string, int](10)
+		for i := 0; i < 100; i++ {
+			key := strconv.Itoa(i)
+			assert.True(t, m.Set(key, i))
+			_, ok := m.Peek(key)
+			assert.True(t, ok)
+		}
+		assert.Equal(t, 10, m.Len())
+	})
+
+	t.Run("RejectsInfrequentCandidate", func(t *testing.T) {
+		m := ringmap.NewTinyLFURingMapOf[string, int](3)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Get("a")
+		m.Get("b")
+		m.Set("c", 3)
+
+		// c leaves the window for d, and is evicted rather than a or b.
+		m.Set("d", 4)
+		assert.ElementsMatch(t, []string{"a", "b", "d"}, m.Keys())
+	})
+
+	t.Run("AdmitsFrequentCandidate", func(t *testing.T) {
+		m := ringmap.NewTinyLFURingMapOf[string, int](3)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		m.Get("c")
+		m.Get("c")
+
+		// c leaves the window for d, and is used more often than a.
+		m.Set("d", 4)
+		assert.ElementsMatch(t, []string{"b", "c", "d"}, m.Keys())
+	})
+
+	t.Run("Unbounded", func(t *testing.T) {
+		m := ringmap.NewTinyLFURingMapOf[int, int](ringmap.Unbounded)
+		for i := 0; i < 1000; i++ {
+			m.Set(i, i)
+			m.Get(i)
+		}
+		assert.Equal(t, 1000, m.Len())
+	})
+}
+
+func TestTinyLFUPolicy_Trace(t *testing.T) {
+	t.Run("ScanKeepsHotset", func(t *testing.T) {
+		hot := keySet("hot", 50)
+		trace := append(loop(hot, 3), keySet("scan", 1000)...)
+
+		m := ringmap.NewTinyLFURingMapOf[string, int](100)
+		replay(m, trace)
+
+		// Only the hot key that was in the window when the scan started
+		// may have lost to a hot key as frequent as itself.
+		assert.GreaterOrEqual(t, replay(m, hot), 0.98)
+	})
+
+	t.Run("HotsetBeatsFIFOAndLRU", func(t *testing.T) {
+		trace := hotsetTrace(rand.New(rand.NewSource(1)), 20000, 50, "")
+
+		fifo := replay(ringmap.NewRingMapOf[string, int](100), trace)
+		lru := replay(ringmap.NewLRURingMapOf[string, int](100), trace)
+		tinyLFU := replay(ringmap.NewTinyLFURingMapOf[string, int](100), trace)
+		assert.Greater(t, tinyLFU, fifo+0.1)
+		assert.Greater(t, tinyLFU, lru+0.05)
+	})
+
+	t.Run("FollowsShift", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		m := ringmap.NewTinyLFURingMapOf[string, int](100)
+		replay(m, hotsetTrace(r, 20000, 50, "old"))
+
+		// Once the sketch has aged, the new hot keys win over the old ones.
+		replay(m, hotsetTrace(r, 5000, 50, "new"))
+		assert.Greater(t, replay(m, hotsetTrace(r, 5000, 50, "new")), 0.45)
+	})
+}
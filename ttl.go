@@ -0,0 +1,133 @@
+package ringmap
+
+import "time"
+
+// Clock tells a map the current time, so that it can expire entries. A Clock
+// used by a SyncRingMap or ShardedRingMap must be safe for concurrent use.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the default Clock, which uses time.Now.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SetClock sets the clock the map uses to expire entries. A nil clock restores
+// the system clock. Changing the clock doesn't change the expiry time of
+// existing entries.
+func (m *RingMapOf[K, V]) SetClock(clock Clock) {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	m.clock = clock
+}
+
+// SetDefaultTTL sets the time to live that Set and Put give to entries. A ttl of
+// zero or less means entries never expire, which is the default. Existing
+// entries keep their expiry time.
+func (m *RingMapOf[K, V]) SetDefaultTTL(ttl time.Duration) {
+	m.ttl = ttl
+}
+
+// DefaultTTL returns the time to live that Set and Put give to entries.
+func (m *RingMapOf[K, V]) DefaultTTL() time.Duration {
+	return m.ttl
+}
+
+// SetWithTTL is like Set, but the entry expires after ttl instead of the
+// default TTL. A ttl of zero or less means the entry never expires. Replacing
+// the value of a key also replaces its expiry time.
+//
+// Once an entry has expired, Get, Peek and GetOrDefault treat it as missing and
+// Len and Keys leave it out. It is removed, and reported with EvictExpired, when
+// its key is set again, when it's at the front of a full map that needs room,
+// or by PurgeExpired.
+func (m *RingMapOf[K, V]) SetWithTTL(key K, value V, ttl time.Duration) bool {
+	added, _ := m.set(key, value, m.expiry(ttl))
+	return added
+}
+
+// PutWithTTL is like Put, but the entry expires after ttl instead of the
+// default TTL. A ttl of zero or less means the entry never expires.
+func (m *RingMapOf[K, V]) PutWithTTL(key K, value V, ttl time.Duration) bool {
+	added, _ := m.put(key, value, m.expiry(ttl))
+	return added
+}
+
+// PurgeExpired removes every expired entry and returns how many were removed.
+func (m *RingMapOf[K, V]) PurgeExpired() int {
+	if m.expiring == 0 {
+		return 0
+	}
+
+	n := 0
+	now := m.clock.Now()
+	for i := m.slots.front; i != none; {
+		next := m.slots.at(i).next
+		if m.isExpired(i, now) {
+			m.remove(i, EvictExpired)
+			n++
+		}
+		i = next
+	}
+
+	return n
+}
+
+// purgeFront removes expired entries from the front of the map, up to the
+// first one that hasn't expired, and returns how many were removed.
+func (m *RingMapOf[K, V]) purgeFront() int {
+	if m.expiring == 0 {
+		return 0
+	}
+
+	n := 0
+	now := m.clock.Now()
+	for i := m.slots.front; i != none && m.isExpired(i, now); i = m.slots.front {
+		m.remove(i, EvictExpired)
+		n++
+	}
+
+	return n
+}
+
+// expiry returns the expiry time for an entry with the given ttl, or the zero
+// time if it never expires.
+func (m *RingMapOf[K, V]) expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+
+	return m.clock.Now().Add(ttl)
+}
+
+// setExpiry sets the expiry time of the slot at index i and keeps count of the
+// elements that have one.
+func (m *RingMapOf[K, V]) setExpiry(i int, expires time.Time) {
+	s := m.slots.at(i)
+	if !s.expires.IsZero() {
+		m.expiring--
+	}
+	if !expires.IsZero() {
+		m.expiring++
+	}
+	s.expires = expires
+}
+
+// isExpired returns true if the slot at index i has expired at now. A zero now
+// means the current time of the map's clock, which is only read if the slot can
+// expire.
+func (m *RingMapOf[K, V]) isExpired(i int, now time.Time) bool {
+	expires := m.slots.at(i).expires
+	if expires.IsZero() {
+		return false
+	}
+	if now.IsZero() {
+		now = m.clock.Now()
+	}
+
+	return !now.Before(expires)
+}
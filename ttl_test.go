@@ -0,0 +1,212 @@
+package ringmap_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a Clock that only moves when told to.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func newTTLMap(capacity int) (*ringmap.RingMapOf[string, int], *fakeClock) {
+	clock := newFakeClock()
+	m := ringmap.NewRingMapOf[string, int](capacity)
+	m.SetClock(clock)
+
+	return m, clock
+}
+
+func TestTTL(t *testing.T) {
+	t.Run("EntryExpires", func(t *testing.T) {
+		m, clock := newTTLMap(ringMapCapacity)
+		m.SetWithTTL("a", 1, time.Minute)
+
+		clock.Advance(59 * time.Second)
+		value, ok := m.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+
+		clock.Advance(time.Second)
+		_, ok = m.Get("a")
+		assert.False(t, ok)
+		_, ok = m.Peek("a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, m.GetOrDefault("a", 0))
+	})
+
+	t.Run("LenAndKeysSkipExpired", func(t *testing.T) {
+		m, clock := newTTLMap(ringMapCapacity)
+		m.SetWithTTL("a", 1, time.Second)
+		m.Set("b", 2)
+		m.PutWithTTL("c", 3, time.Hour)
+		assert.Equal(t, 3, m.Len())
+
+		clock.Advance(time.Second)
+		assert.Equal(t, 2, m.Len())
+		assert.Equal(t, []string{"b", "c"}, m.Keys())
+	})
+
+	t.Run("DefaultTTL", func(t *testing.T) {
+		m, clock := newTTLMap(ringMapCapacity)
+		m.SetDefaultTTL(time.Minute)
+		assert.Equal(t, time.Minute, m.DefaultTTL())
+		m.Set("a", 1)
+		m.Put("b", 2)
+		m.SetWithTTL("c", 3, 0)
+
+		clock.Advance(time.Minute)
+		assert.Equal(t, []string{"c"}, m.Keys())
+	})
+
+	t.Run("SetRenewsExpiry", func(t *testing.T) {
+		m, clock := newTTLMap(ringMapCapacity)
+		m.SetWithTTL("a", 1, time.Minute)
+		clock.Advance(30 * time.Second)
+		assert.False(t, m.SetWithTTL("a", 2, time.Minute))
+
+		clock.Advance(45 * time.Second)
+		value, ok := m.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("SetOfExpiredKeyIsNew", func(t *testing.T) {
+		m, clock := newTTLMap(ringMapCapacity)
+		evictions := recordEvictions(m)
+		m.SetWithTTL("a", 1, time.Second)
+		m.Set("b", 2)
+		clock.Advance(time.Second)
+
+		assert.True(t, m.Set("a", 3))
+		assert.Equal(t, []string{"b", "a"}, m.Keys())
+		assert.False(t, m.Put("a", 4))
+		assert.Equal(t, []eviction{
+			{"a", 1, ringmap.EvictExpired},
+			{"a", 3, ringmap.EvictReplaced},
+		}, *evictions)
+	})
+
+	t.Run("PutOfExpiredKeyIsNew", func(t *testing.T) {
+		m, clock := newTTLMap(ringMapCapacity)
+		m.SetWithTTL("a", 1, time.Second)
+		clock.Advance(time.Second)
+		assert.True(t, m.Put("a", 2))
+	})
+
+	t.Run("EvictionDropsExpiredFrontFirst", func(t *testing.T) {
+		m, clock := newTTLMap(3)
+		evictions := recordEvictions(m)
+		m.SetWithTTL("a", 1, time.Second)
+		m.SetWithTTL("b", 2, time.Second)
+		m.Set("c", 3)
+		clock.Advance(time.Second)
+
+		m.Set("d", 4)
+		assert.Equal(t, []string{"c", "d"}, m.Keys())
+		assert.Equal(t, []eviction{
+			{"a", 1, ringmap.EvictExpired},
+			{"b", 2, ringmap.EvictExpired},
+		}, *evictions)
+
+		// Nothing expired at the front, so the oldest live entry goes.
+		m.Set("e", 5)
+		m.Set("f", 6)
+		assert.Equal(t, []string{"d", "e", "f"}, m.Keys())
+	})
+
+	t.Run("PurgeExpired", func(t *testing.T) {
+		m, clock := newTTLMap(ringMapCapacity)
+		evictions := recordEvictions(m)
+		m.Set("a", 1)
+		m.SetWithTTL("b", 2, time.Second)
+		m.Set("c", 3)
+		m.SetWithTTL("d", 4, time.Hour)
+		assert.Equal(t, 0, m.PurgeExpired())
+
+		clock.Advance(time.Second)
+		assert.Equal(t, 1, m.PurgeExpired())
+		assert.Equal(t, []eviction{{"b", 2, ringmap.EvictExpired}}, *evictions)
+		assert.Equal(t, []string{"a", "c", "d"}, m.Keys())
+		assert.Equal(t, "a", m.Front().Key)
+	})
+
+	t.Run("DeleteOfExpiringEntry", func(t *testing.T) {
+		m, clock := newTTLMap(ringMapCapacity)
+		evictions := recordEvictions(m)
+		m.SetWithTTL("a", 1, time.Second)
+		m.SetWithTTL("b", 2, time.Second)
+		assert.True(t, m.Delete("a"))
+		m.Set("c", 3)
+		clock.Advance(time.Second)
+		assert.False(t, m.Delete("b"))
+		assert.Equal(t, 1, m.Len())
+		assert.Equal(t, []eviction{
+			{"a", 1, ringmap.EvictDeleted},
+			{"b", 2, ringmap.EvictExpired},
+		}, *evictions)
+	})
+
+	t.Run("NilClockRestoresSystemClock", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.SetClock(nil)
+		m.SetWithTTL("a", 1, time.Hour)
+		_, ok := m.Get("a")
+		assert.True(t, ok)
+	})
+}
+
+func TestSyncRingMap_TTL(t *testing.T) {
+	clock := newFakeClock()
+	m := ringmap.NewSyncRingMap[string, int](ringMapCapacity)
+	m.SetClock(clock)
+	m.SetDefaultTTL(time.Minute)
+	m.Set("a", 1)
+	m.SetWithTTL("b", 2, time.Hour)
+	m.PutWithTTL("c", 3, 0)
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, 2, m.Len())
+	assert.Equal(t, 1, m.PurgeExpired())
+	assert.Equal(t, []string{"b", "c"}, m.Keys())
+}
+
+func TestShardedRingMap_TTL(t *testing.T) {
+	clock := newFakeClock()
+	m := ringmap.NewShardedRingMap[string, int](ringMapCapacity, 4)
+	m.SetClock(clock)
+	m.SetDefaultTTL(time.Minute)
+	m.Set("a", 1)
+	m.SetWithTTL("b", 2, time.Hour)
+	m.PutWithTTL("c", 3, 0)
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, 2, m.Len())
+	assert.Equal(t, 1, m.PurgeExpired())
+	_, ok := m.Get("a")
+	assert.False(t, ok)
+}
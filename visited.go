@@ -0,0 +1,56 @@
+package ringmap
+
+import "sync/atomic"
+
+// visitedBits holds a visited bit for every key of a map. Bits are set with
+// atomic stores, so set may be called by many goroutines at once, as long as
+// add and remove aren't.
+type visitedBits[K comparable] struct {
+	index map[K]int
+	bits  []uint32
+	free  []int // indexes of bits that are no longer used
+}
+
+func newVisitedBits[K comparable]() visitedBits[K] {
+	return visitedBits[K]{index: make(map[K]int)}
+}
+
+// add gives key a bit that isn't set.
+func (v *visitedBits[K]) add(key K) {
+	var i int
+	if n := len(v.free); n > 0 {
+		i = v.free[n-1]
+		v.free = v.free[:n-1]
+		v.bits[i] = 0
+	} else {
+		i = len(v.bits)
+		v.bits = append(v.bits, 0)
+	}
+	v.index[key] = i
+}
+
+// remove takes the bit of key away.
+func (v *visitedBits[K]) remove(key K) {
+	if i, ok := v.index[key]; ok {
+		delete(v.index, key)
+		v.free = append(v.free, i)
+	}
+}
+
+// set sets the bit of key. It doesn't write the bit if it's set already, to
+// keep concurrent readers of the same key from contending.
+func (v *visitedBits[K]) set(key K) {
+	if i, ok := v.index[key]; ok && atomic.LoadUint32(&v.bits[i]) == 0 {
+		atomic.StoreUint32(&v.bits[i], 1)
+	}
+}
+
+// clear clears the bit of key and returns whether it was set.
+func (v *visitedBits[K]) clear(key K) bool {
+	i, ok := v.index[key]
+	if !ok {
+		return false
+	}
+
+	return atomic.SwapUint32(&v.bits[i], 0) == 1
+}
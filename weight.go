@@ -0,0 +1,67 @@
+package ringmap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Sizer is implemented by values that know their own weight, typically their
+// size in bytes. It is used when a map has no Weigher.
+type Sizer interface {
+	Size() int64
+}
+
+// Weigher returns the weight of an entry towards the maximum weight of a map.
+// Negative weights count as zero.
+type Weigher[K comparable, V any] func(key K, value V) int64
+
+// sizerType is the reflect.Type of Sizer.
+var sizerType = reflect.TypeOf((*Sizer)(nil)).Elem()
+
+// mayBeSizer returns true if values of type V may implement Sizer: either V
+// implements it, or V is an interface type whose values might.
+func mayBeSizer[V any]() bool {
+	t := reflect.TypeOf((*V)(nil)).Elem()
+	return t.Kind() == reflect.Interface || t.Implements(sizerType)
+}
+
+// Weight returns the total weight of the entries in the map, including expired
+// entries that haven't been removed yet.
+func (m *RingMapOf[K, V]) Weight() int64 {
+	return m.weight
+}
+
+// MaxWeight returns the maximum total weight of the entries in the map, or
+// Unbounded if there is none.
+func (m *RingMapOf[K, V]) MaxWeight() int64 {
+	return m.maxWeight
+}
+
+// weigh returns the weight of an entry. It returns ErrOversized if the entry
+// could never fit in the map.
+func (m *RingMapOf[K, V]) weigh(key K, value V) (int64, error) {
+	weight := int64(1)
+	if m.weigher != nil {
+		weight = m.weigher(key, value)
+	} else if m.sizer {
+		if s, ok := interface{}(value).(Sizer); ok {
+			weight = s.Size()
+		}
+	}
+	if weight < 0 {
+		weight = 0
+	}
+
+	if m.maxWeight >= 0 && weight > m.maxWeight {
+		return 0, fmt.Errorf("%w: %d > %d", ErrOversized, weight, m.maxWeight)
+	}
+
+	return weight, nil
+}
+
+// setWeight sets the weight of the slot at index i and keeps the total weight.
+func (m *RingMapOf[K, V]) setWeight(i int, weight int64) {
+	s := m.slots.at(i)
+	m.weight += weight - s.weight
+	s.weight = weight
+}
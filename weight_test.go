@@ -0,0 +1,165 @@
+package ringmap_test
+
+import (
+	"testing"
+
+	"github.com/prgsmall/ringmap"
+	"github.com/stretchr/testify/assert"
+)
+
+// blob is a value that weighs its length.
+type blob string
+
+func (b blob) Size() int64 {
+	return int64(len(b))
+}
+
+func newWeightedMap(t *testing.T, maxWeight int64) *ringmap.RingMapOf[string, []byte] {
+	m, err := ringmap.New[string, []byte](
+		ringmap.WithCapacity(ringmap.Unbounded),
+		ringmap.WithMaxWeight(maxWeight),
+		ringmap.WithWeigher(func(key string, value []byte) int64 {
+			return int64(len(value))
+		}),
+	)
+	assert.NoError(t, err)
+
+	return m
+}
+
+func TestWeight(t *testing.T) {
+	t.Run("DefaultsToOnePerEntry", func(t *testing.T) {
+		m := ringmap.NewRingMapOf[string, int](ringMapCapacity)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		assert.Equal(t, int64(2), m.Weight())
+		assert.Equal(t, int64(ringmap.Unbounded), m.MaxWeight())
+
+		m.Delete("a")
+		assert.Equal(t, int64(1), m.Weight())
+	})
+
+	t.Run("EvictsFromFrontUntilItFits", func(t *testing.T) {
+		m := newWeightedMap(t, 10)
+		m.Set("a", make([]byte, 4))
+		m.Set("b", make([]byte, 4))
+		m.Set("c", make([]byte, 2))
+		assert.Equal(t, int64(10), m.Weight())
+
+		var evicted []string
+		m.OnEvict(func(key string, value []byte, reason ringmap.EvictReason) {
+			assert.Equal(t, ringmap.EvictCapacity, reason)
+			evicted = append(evicted, key)
+		})
+		m.Set("d", make([]byte, 7))
+		assert.Equal(t, []string{"c", "d"}, m.Keys())
+		assert.Equal(t, int64(9), m.Weight())
+		assert.Equal(t, []string{"a", "b"}, evicted)
+	})
+
+	t.Run("RejectsOversizedEntries", func(t *testing.T) {
+		m := newWeightedMap(t, 10)
+		m.Set("a", make([]byte, 4))
+
+		added, err := m.TrySet("b", make([]byte, 11))
+		assert.False(t, added)
+		assert.ErrorIs(t, err, ringmap.ErrOversized)
+		_, err = m.TryPut("a", make([]byte, 11))
+		assert.ErrorIs(t, err, ringmap.ErrOversized)
+
+		assert.Equal(t, []string{"a"}, m.Keys())
+		assert.Equal(t, int64(4), m.Weight())
+	})
+
+	t.Run("SetReplacesWeight", func(t *testing.T) {
+		m := newWeightedMap(t, 10)
+		m.Set("a", make([]byte, 4))
+		m.Set("b", make([]byte, 4))
+
+		m.Set("a", make([]byte, 2))
+		assert.Equal(t, []string{"a", "b"}, m.Keys())
+		assert.Equal(t, int64(6), m.Weight())
+	})
+
+	t.Run("HeavierValueMakesRoom", func(t *testing.T) {
+		m := newWeightedMap(t, 10)
+		m.Set("a", make([]byte, 4))
+		m.Set("b", make([]byte, 4))
+
+		m.Set("a", make([]byte, 8))
+		assert.Equal(t, []string{"a"}, m.Keys())
+		assert.Equal(t, int64(8), m.Weight())
+	})
+
+	t.Run("FailedReplaceKeepsOldValue", func(t *testing.T) {
+		m, err := ringmap.New[int, int](
+			ringmap.WithCapacity(ringmap.Unbounded),
+			ringmap.WithMaxWeight(10),
+			ringmap.WithPolicy[int](&pinnedPolicy{}),
+			ringmap.WithWeigher(func(key int, value int) int64 {
+				return int64(value)
+			}),
+		)
+		assert.NoError(t, err)
+		m.Set(1, 5)
+		m.Set(2, 5)
+
+		_, err = m.TrySet(1, 6)
+		assert.ErrorIs(t, err, ringmap.ErrFull)
+		_, err = m.TryPut(1, 6)
+		assert.ErrorIs(t, err, ringmap.ErrFull)
+
+		value, _ := m.Get(1)
+		assert.Equal(t, 5, value)
+		assert.Equal(t, []int{1, 2}, m.Keys())
+		assert.Equal(t, int64(10), m.Weight())
+	})
+
+	t.Run("Sizer", func(t *testing.T) {
+		m, err := ringmap.New[string, blob](
+			ringmap.WithCapacity(ringMapCapacity),
+			ringmap.WithMaxWeight(5),
+		)
+		assert.NoError(t, err)
+		m.Set("a", "abc")
+		m.Set("b", "de")
+		m.Set("c", "f")
+		assert.Equal(t, []string{"b", "c"}, m.Keys())
+		assert.Equal(t, int64(3), m.Weight())
+	})
+
+	t.Run("UntypedSizer", func(t *testing.T) {
+		m := ringmap.NewRingMap(ringMapCapacity)
+		m.Set("a", blob("abc"))
+		m.Set("b", 1)
+		assert.Equal(t, int64(4), m.Weight())
+	})
+
+	t.Run("CapacityStillApplies", func(t *testing.T) {
+		m, err := ringmap.New[string, int](
+			ringmap.WithCapacity(2),
+			ringmap.WithMaxWeight(100),
+		)
+		assert.NoError(t, err)
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		assert.Equal(t, []string{"b", "c"}, m.Keys())
+	})
+
+	t.Run("InvalidMaxWeight", func(t *testing.T) {
+		_, err := ringmap.New[string, int](
+			ringmap.WithCapacity(2),
+			ringmap.WithMaxWeight(-2),
+		)
+		assert.ErrorIs(t, err, ringmap.ErrInvalidWeight)
+	})
+
+	t.Run("WeigherForOtherTypes", func(t *testing.T) {
+		_, err := ringmap.New[string, int](
+			ringmap.WithCapacity(2),
+			ringmap.WithWeigher(func(key int, value int) int64 { return 1 }),
+		)
+		assert.ErrorIs(t, err, ringmap.ErrInvalidOption)
+	})
+}